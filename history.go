@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/yannickalex07/dmon/pkg/config"
+	"github.com/yannickalex07/dmon/pkg/history"
+)
+
+// runHistory prints the events of the history that match the CLI arguments.
+func runHistory(ctx context.Context, args []string) {
+	flags := flag.NewFlagSet("history", flag.ExitOnError)
+	configPath := flags.String("c", "./config.yaml", "Path to the config file")
+	jobId := flags.String("job", "", "Only show events of the job with this id")
+	event := flags.String("event", "", "Only show events of this type, i.e. failure")
+	since := flags.String("since", "", "Only show events since a duration (i.e. 24h) or an RFC 3339 timestamp")
+	limit := flags.Int("limit", history.DefaultLimit, "Maximum number of events, 0 shows all events")
+	asJSON := flags.Bool("json", false, "Print the events as JSON")
+	flags.Parse(args)
+
+	cfg, err := config.Read(*configPath)
+	if err != nil {
+		errStr := fmt.Sprintf("Failed to parse config => %s", err.Error())
+		log.Fatal(errStr)
+	}
+
+	if !cfg.History.Enabled {
+		log.Fatal("The history is not enabled in the config")
+	}
+
+	sinceTime, err := history.ParseSince(*since, time.Now())
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	store, err := buildHistory(cfg)
+	if err != nil {
+		errStr := fmt.Sprintf("Failed to open history => %s", err.Error())
+		log.Fatal(errStr)
+	}
+	defer store.Close()
+
+	events, err := store.Events(ctx, history.Query{
+		JobId: *jobId,
+		Event: *event,
+		Since: sinceTime,
+		Limit: *limit,
+	})
+	if err != nil {
+		errStr := fmt.Sprintf("Failed to query history => %s", err.Error())
+		log.Fatal(errStr)
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(events)
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tEVENT\tJOB\tSTATE\tDELIVERIES")
+
+	for _, e := range events {
+		fmt.Fprintf(w, "%s\t%s\t%s (%s)\t%s\t%s\n", e.Time.Format(time.RFC3339), e.Event, e.JobName, e.JobId, e.State, formatDeliveries(e.Deliveries))
+	}
+
+	w.Flush()
+}
+
+func formatDeliveries(deliveries []history.Delivery) string {
+	if len(deliveries) == 0 {
+		return "-"
+	}
+
+	formatted := make([]string, 0, len(deliveries))
+	for _, d := range deliveries {
+		if d.Success {
+			formatted = append(formatted, d.Handler+": ok")
+			continue
+		}
+
+		formatted = append(formatted, fmt.Sprintf("%s: failed (%s)", d.Handler, d.Error))
+	}
+
+	return strings.Join(formatted, ", ")
+}
@@ -5,19 +5,36 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/go-co-op/gocron"
+	"github.com/redis/go-redis/v9"
 	log "github.com/sirupsen/logrus"
 	"github.com/yannickalex07/dmon/pkg/config"
+	"github.com/yannickalex07/dmon/pkg/dashboard"
 	"github.com/yannickalex07/dmon/pkg/dataflow"
 	"github.com/yannickalex07/dmon/pkg/handler"
+	"github.com/yannickalex07/dmon/pkg/health"
+	"github.com/yannickalex07/dmon/pkg/history"
+	"github.com/yannickalex07/dmon/pkg/interaction"
+	"github.com/yannickalex07/dmon/pkg/lock"
+	"github.com/yannickalex07/dmon/pkg/metrics"
 	"github.com/yannickalex07/dmon/pkg/monitor"
+	"github.com/yannickalex07/dmon/pkg/server"
 	"github.com/yannickalex07/dmon/pkg/storage"
 )
 
 func main() {
-	ctx := context.Background()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// the history subcommand only queries the event history
+	if len(os.Args) > 1 && os.Args[1] == "history" {
+		runHistory(ctx, os.Args[2:])
+		return
+	}
 
 	// parse CLI arguments
 	configPath := flag.String("c", "./config.yaml", "Path to the config file")
@@ -44,41 +61,340 @@ func main() {
 	log.SetOutput(os.Stdout)
 
 	// build dataflow client
-	client := dataflow.DataflowClient{
-		Project:  cfg.Project.Id,
-		Location: cfg.Project.Location,
-	}
-
-	// build handlers
-	handlers := make([]handler.Handler, 0)
-
-	// slack handler
-	slackHandler := handler.SlackHandler{
-		Token:                 cfg.Slack.Token,
-		Channel:               cfg.Slack.Channel,
-		IncludeErrorSection:   cfg.Slack.IncludeErrorSection,
-		IncludeDataflowButton: cfg.Slack.IncludeDataflowButton,
-		GCPConfig: handler.SlackGCPConfig{
-			Id:       cfg.Project.Id,
-			Location: cfg.Project.Location,
-		},
+	projects, err := cfg.ProjectConfigs()
+	if err != nil {
+		errStr := fmt.Sprintf("Failed to read project config => %s", err.Error())
+		log.Fatal(errStr)
+	}
+
+	filter, err := dataflow.NewFilter(cfg.Filters)
+	if err != nil {
+		errStr := fmt.Sprintf("Failed to read filter config => %s", err.Error())
+		log.Fatal(errStr)
 	}
 
-	handlers = append(handlers, slackHandler)
+	dataflowClient := buildDataflowClient(projects, filter)
+
+	// failed requests are counted for the metrics endpoint
+	client := metrics.Dataflow{Client: dataflowClient}
 
 	// setup state storage
-	stateStore := storage.NewMemoryStore(cfg.ExpireTimeoutDuration())
+	stateStore, err := buildStorage(cfg)
+	if err != nil {
+		errStr := fmt.Sprintf("Failed to setup storage => %s", err.Error())
+		log.Fatal(errStr)
+	}
+
+	// build handlers, jobs carry their own project and location,
+	// so the first project is only used as a fallback.
+	gcpConfig := handler.GCPConfig{
+		Id: projects[0].Id,
+	}
+
+	if locations := projects[0].LocationList(); len(locations) > 0 {
+		gcpConfig.Location = locations[0]
+	}
+
+	handlerEnv := handler.Env{GCP: gcpConfig}
+	if values, ok := stateStore.(storage.ValueStorage); ok {
+		handlerEnv.Store = values
+	}
+
+	handlerConfigs, err := cfg.HandlerConfigs()
+	if err != nil {
+		errStr := fmt.Sprintf("Failed to read handler config => %s", err.Error())
+		log.Fatal(errStr)
+	}
+
+	namedHandlers, err := handler.BuildNamed(handlerConfigs, handlerEnv)
+	if err != nil {
+		errStr := fmt.Sprintf("Failed to setup handlers => %s", err.Error())
+		log.Fatal(errStr)
+	}
+
+	if len(namedHandlers) == 0 {
+		log.Warn("No handlers are configured, failures and timeouts will only be logged.")
+	}
+
+	// the router takes care of forwarding events only to the handlers that should receive them.
+	// Without any routes it forwards every event to all handlers.
+	router, err := handler.NewRouter(cfg.Routing, namedHandlers)
+	if err != nil {
+		errStr := fmt.Sprintf("Failed to setup routing => %s", err.Error())
+		log.Fatal(errStr)
+	}
+
+	// setup event history
+	var historyStore history.Store
+	if cfg.History.Enabled {
+		historyStore, err = buildHistory(cfg)
+		if err != nil {
+			errStr := fmt.Sprintf("Failed to setup history => %s", err.Error())
+			log.Fatal(errStr)
+		}
+		defer historyStore.Close()
+
+		router.History = historyStore
+	}
+
+	handlers := []handler.Handler{router}
+
+	// the board records the jobs and events of every run for the dashboard
+	var board *dashboard.Board
+	if cfg.Server.Dashboard {
+		board = dashboard.NewBoard(dashboard.DefaultMaxEvents)
+		handlers = []handler.Handler{dashboard.Handler{Handler: router, Board: board}}
+	}
+
+	// setup HTTP server
+	if cfg.Interactions.SlackEnabled() && !cfg.Server.Enabled() {
+		log.Fatal("Slack interactions require a server address")
+	}
+
+	if cfg.Server.Metrics && !cfg.Server.Enabled() {
+		log.Fatal("Metrics require a server address")
+	}
+
+	if cfg.Server.Dashboard && !cfg.Server.Enabled() {
+		log.Fatal("The dashboard requires a server address")
+	}
+
+	if cfg.Server.Health.Enabled && !cfg.Server.Enabled() {
+		log.Fatal("Health endpoints require a server address")
+	}
+
+	// the tracker records every run, so that /readyz can report if runs stopped succeeding
+	maxRunAge := time.Duration(cfg.RequestInterval*cfg.Server.Health.RequiredIntervals()) * time.Minute
+	tracker := health.NewTracker(maxRunAge)
+
+	if cfg.Server.Enabled() {
+		srv := server.New(cfg.Server.Address)
+
+		if cfg.Server.Metrics {
+			srv.Handle("/metrics", metrics.Handler())
+		}
+
+		if cfg.Server.Health.Enabled {
+			srv.Handle("/healthz", tracker.Healthz())
+			srv.Handle("/readyz", tracker.Readyz())
+		}
+
+		if board != nil {
+			srv.Handle("/", board.PageHandler())
+			srv.Handle("/api/jobs", board.JobsHandler())
+			srv.Handle("/api/events", board.EventsHandler())
+		}
+
+		if historyStore != nil {
+			srv.Handle("/api/history", history.Handler(historyStore))
+		}
+
+		if cfg.Interactions.SlackEnabled() {
+			srv.Handle(cfg.Interactions.SlackPath(), interaction.SlackActions{
+				SigningSecret: cfg.Interactions.Slack.SigningSecret,
+				Dataflow:      client,
+				Store:         handlerEnv.Store,
+			})
+		}
+
+		go func() {
+			err := srv.Run(ctx)
+			if err != nil {
+				log.Fatalf("Failed to run server => %s", err.Error())
+			}
+		}()
+	}
 
 	// setup and start monitor
+	timeoutRules, err := monitor.NewTimeoutRules(cfg.Timeout.Rules)
+	if err != nil {
+		errStr := fmt.Sprintf("Failed to read timeout rules => %s", err.Error())
+		log.Fatal(errStr)
+	}
+
+	stuckStates, err := monitor.NewStuckStates(cfg.Timeout.StuckStates)
+	if err != nil {
+		errStr := fmt.Sprintf("Failed to read stuck states => %s", err.Error())
+		log.Fatal(errStr)
+	}
+
+	remediation, err := monitor.NewRemediationConfig(cfg.Remediation)
+	if err != nil {
+		errStr := fmt.Sprintf("Failed to read remediation config => %s", err.Error())
+		log.Fatal(errStr)
+	}
+
+	retryRules, err := monitor.NewRetryRules(cfg.Retry.Rules)
+	if err != nil {
+		errStr := fmt.Sprintf("Failed to read retry rules => %s", err.Error())
+		log.Fatal(errStr)
+	}
+
 	monCfg := monitor.MonitorConfig{
 		MaxJobTimeout: cfg.MaxTimeoutDuration(),
+		TimeoutRules:  timeoutRules,
+		Streaming: monitor.StreamingConfig{
+			MaxDataWatermarkAge: cfg.Streaming.MaxDataWatermarkAgeDuration(),
+			MaxSystemLag:        cfg.Streaming.MaxSystemLagDuration(),
+			ConsecutiveChecks:   cfg.Streaming.RequiredChecks(),
+			Tracker:             monitor.NewHealthTracker(),
+		},
+		StuckStates:        stuckStates,
+		HardLimit:          cfg.HardLimitDuration(),
+		StreamingHardLimit: cfg.StreamingHardLimitDuration(),
+		Remediation:        remediation,
+		RetryRules:         retryRules,
+	}
+
+	if board != nil {
+		monCfg.Observer = board
+	}
+
+	// setup leader election
+	var elector *lock.Elector
+	if cfg.LeaderElection.Enabled {
+		leaderLock, err := buildLock(cfg)
+		if err != nil {
+			errStr := fmt.Sprintf("Failed to setup leader election => %s", err.Error())
+			log.Fatal(errStr)
+		}
+
+		elector = &lock.Elector{
+			Lock:          leaderLock,
+			LeaseDuration: cfg.LeaseDuration(),
+		}
+
+		go elector.Run(ctx)
 	}
 
 	monitorFunc := func() {
-		monitor.Monitor(ctx, monCfg, client, handlers, stateStore)
+		startedAt := time.Now()
+
+		if elector != nil && !elector.Renew(ctx) {
+			log.Info("Skipping run because this instance is not the leader.")
+
+			// followers only check their access, so that they are ready to take over
+			err := dataflowClient.Check(ctx)
+			if err != nil {
+				log.Errorf("failed to request the Dataflow API: %s", err.Error())
+			}
+
+			tracker.Record(health.Run{StartedAt: startedAt, FinishedAt: time.Now(), Err: err, Skipped: true})
+			return
+		}
+
+		err := monitor.Monitor(ctx, monCfg, client, handlers, stateStore)
+		tracker.Record(health.Run{StartedAt: startedAt, FinishedAt: time.Now(), Err: err})
 	}
 
 	scheduler := gocron.NewScheduler(time.UTC)
 	scheduler.Every(cfg.RequestInterval).Minute().Do(monitorFunc)
-	scheduler.StartBlocking()
+
+	if historyStore != nil {
+		scheduler.Every(1).Hour().Do(func() {
+			deleted, err := historyStore.Prune(ctx, time.Now().Add(-cfg.History.RetentionDuration()))
+			if err != nil {
+				log.Errorf("failed to prune history: %s", err.Error())
+				return
+			}
+
+			log.Debugf("Pruned %d events from the history", deleted)
+		})
+	}
+	scheduler.StartAsync()
+
+	// wait for shutdown
+	<-ctx.Done()
+
+	log.Info("Shutting down.")
+	scheduler.Stop()
+
+	if elector != nil {
+		err := elector.Release(context.Background())
+		if err != nil {
+			log.Errorf("failed to release leader lease: %s", err.Error())
+		}
+	}
+}
+
+// buildDataflowClient creates a client for every location of every project.
+// Projects without locations are listed across all locations at once.
+func buildDataflowClient(projects []config.ProjectConfig, filter dataflow.Filter) dataflow.MultiClient {
+	client := dataflow.MultiClient{}
+	for _, p := range projects {
+		if p.AllLocations() {
+			client.Clients = append(client.Clients, dataflow.DataflowClient{Project: p.Id, Filter: filter, Details: dataflow.NewDetailsCache()})
+			continue
+		}
+
+		for _, location := range p.LocationList() {
+			client.Clients = append(client.Clients, dataflow.DataflowClient{Project: p.Id, Location: location, Filter: filter, Details: dataflow.NewDetailsCache()})
+		}
+	}
+
+	return client
+}
+
+func buildStorage(cfg *config.Config) (storage.Storage, error) {
+	switch cfg.Storage.Type {
+	case "", "memory":
+		return storage.NewMemoryStore(cfg.ExpireTimeoutDuration()), nil
+	case "file":
+		if cfg.Storage.File.Path == "" {
+			return nil, fmt.Errorf("storage type file requires a path")
+		}
+
+		return storage.NewFileStore(cfg.Storage.File.Path, cfg.ExpireTimeoutDuration())
+	case "redis":
+		client := newRedisClient(cfg.Storage.Redis)
+		return storage.NewRedisStore(client, cfg.Storage.Redis.KeyPrefix(), cfg.ExpireTimeoutDuration()), nil
+	default:
+		return nil, fmt.Errorf("unknown storage type %s", cfg.Storage.Type)
+	}
+}
+
+func buildLock(cfg *config.Config) (lock.Lock, error) {
+	identity := cfg.LeaderElection.Identity
+	if identity == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("failed to determine identity: %w", err)
+		}
+
+		identity = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+
+	switch cfg.LeaderElection.Type {
+	case "file":
+		if cfg.LeaderElection.File.Path == "" {
+			return nil, fmt.Errorf("leader election type file requires a path")
+		}
+
+		return lock.NewFileLock(cfg.LeaderElection.File.Path, identity), nil
+	case "redis":
+		client := newRedisClient(cfg.LeaderElection.Redis)
+		return lock.NewRedisLock(client, cfg.LeaderElection.Redis.KeyPrefix()+"leader", identity), nil
+	default:
+		return nil, fmt.Errorf("unknown leader election type %s", cfg.LeaderElection.Type)
+	}
+}
+
+func buildHistory(cfg *config.Config) (history.Store, error) {
+	switch cfg.History.Type {
+	case "", "sqlite":
+		return history.NewSQLiteStore(cfg.History.SQLitePath())
+	case "memory":
+		return history.NewMemoryStore(), nil
+	default:
+		return nil, fmt.Errorf("unknown history type %s", cfg.History.Type)
+	}
+}
+
+func newRedisClient(cfg config.RedisConfig) *redis.Client {
+	return redis.NewClient(&redis.Options{
+		Addr:     cfg.Address,
+		Username: cfg.Username,
+		Password: cfg.Password,
+		DB:       cfg.DB,
+	})
 }
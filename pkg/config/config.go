@@ -10,27 +10,256 @@ type Config struct {
 	}
 
 	Timeout struct {
-		MaxTimeout    int `yaml:"max_timeout_duration"`
-		ExpireTimeout int `yaml:"expire_timeout_duration"`
+		MaxTimeout         int                 `yaml:"max_timeout_duration"`
+		HardLimit          int                 `yaml:"hard_limit_duration"`
+		StreamingHardLimit int                 `yaml:"streaming_hard_limit_duration"`
+		ExpireTimeout      int                 `yaml:"expire_timeout_duration"`
+		Rules              []TimeoutRuleConfig `yaml:"rules"`
+		StuckStates        map[string]int      `yaml:"stuck_states"`
 	} `yaml:"timeout"`
 
-	Project struct {
-		Id       string `yaml:"id"`
-		Location string `yaml:"location"`
-	} `yaml:"project"`
+	Remediation RemediationConfig `yaml:"remediation"`
 
+	Retry struct {
+		Rules []RetryRuleConfig `yaml:"rules"`
+	} `yaml:"retry"`
+
+	Streaming StreamingConfig `yaml:"streaming"`
+
+	Storage struct {
+		Type string `yaml:"type"`
+
+		File struct {
+			Path string `yaml:"path"`
+		} `yaml:"file"`
+
+		Redis RedisConfig `yaml:"redis"`
+	} `yaml:"storage"`
+
+	LeaderElection struct {
+		Enabled       bool   `yaml:"enabled"`
+		Type          string `yaml:"type"`
+		Identity      string `yaml:"identity"`
+		LeaseDuration int    `yaml:"lease_duration"`
+
+		File struct {
+			Path string `yaml:"path"`
+		} `yaml:"file"`
+
+		Redis RedisConfig `yaml:"redis"`
+	} `yaml:"leader_election"`
+
+	Server       ServerConfig       `yaml:"server"`
+	Interactions InteractionsConfig `yaml:"interactions"`
+	History      HistoryConfig      `yaml:"history"`
+
+	Projects []ProjectConfig `yaml:"projects"`
+	Filters  FilterConfig    `yaml:"filters"`
+
+	Handlers []HandlerConfig `yaml:"handlers"`
+	Routing  RoutingConfig   `yaml:"routing"`
+
+	// Deprecated: configures a single project, use the projects list instead.
+	Project ProjectConfig `yaml:"project"`
+
+	// Deprecated: the following sections configure a single handler of each type,
+	// use the handlers list instead.
+
+	Slack     SlackConfig     `yaml:"slack"`
+	Webhook   WebhookConfig   `yaml:"webhook"`
+	Email     EmailConfig     `yaml:"email"`
+	PagerDuty PagerDutyConfig `yaml:"pagerduty"`
+	Teams     TeamsConfig     `yaml:"teams"`
+}
+
+// TimeoutRuleConfig overrides the max timeout for all jobs that match the rule.
+// Name is a regex for the job name, both the name and the labels have to match.
+type TimeoutRuleConfig struct {
+	Name       string            `yaml:"name"`
+	Labels     map[string]string `yaml:"labels"`
+	MaxTimeout int               `yaml:"max_timeout_duration"`
+	HardLimit  int               `yaml:"hard_limit_duration"`
+}
+
+func (c TimeoutRuleConfig) MaxTimeoutDuration() time.Duration {
+	return time.Duration(c.MaxTimeout) * time.Minute
+}
+
+func (c TimeoutRuleConfig) HardLimitDuration() time.Duration {
+	return time.Duration(c.HardLimit) * time.Minute
+}
+
+// RetryRuleConfig relaunches failed jobs that match the rule from their template.
+// Name is a regex for the job name, both the name and the labels have to match.
+type RetryRuleConfig struct {
+	Name         string            `yaml:"name"`
+	Labels       map[string]string `yaml:"labels"`
+	MaxRetries   int               `yaml:"max_retries"`
+	Backoff      int               `yaml:"backoff"`
+	TemplatePath string            `yaml:"template_path"`
+	FlexTemplate bool              `yaml:"flex_template"`
+	Parameters   []string          `yaml:"parameters"`
+}
+
+func (c RetryRuleConfig) BackoffDuration() time.Duration {
+	return time.Duration(c.Backoff) * time.Minute
+}
+
+// RemediationConfig controls the actions for jobs that exceed their hard limit.
+// Allowlist contains regexes for the names of jobs that are never touched.
+type RemediationConfig struct {
+	DryRun    bool     `yaml:"dry_run"`
+	Allowlist []string `yaml:"allowlist"`
+}
+
+// StreamingConfig configures the health checks of running streaming jobs.
+// Thresholds are in seconds, a threshold of zero disables the check.
+type StreamingConfig struct {
+	MaxDataWatermarkAge int `yaml:"max_data_watermark_age"`
+	MaxSystemLag        int `yaml:"max_system_lag"`
+	ConsecutiveChecks   int `yaml:"consecutive_checks"`
+}
+
+func (c StreamingConfig) MaxDataWatermarkAgeDuration() time.Duration {
+	return time.Duration(c.MaxDataWatermarkAge) * time.Second
+}
+
+func (c StreamingConfig) MaxSystemLagDuration() time.Duration {
+	return time.Duration(c.MaxSystemLag) * time.Second
+}
+
+// RequiredChecks returns the number of consecutive unhealthy checks before
+// a job is reported, which is at least one.
+func (c StreamingConfig) RequiredChecks() int {
+	if c.ConsecutiveChecks <= 0 {
+		return 1
+	}
+
+	return c.ConsecutiveChecks
+}
+
+// ServerConfig configures the HTTP server of dmon, it is only started if an address is set.
+type ServerConfig struct {
+	Address string `yaml:"address"`
+
+	// Metrics exposes Prometheus metrics under /metrics.
+	Metrics bool `yaml:"metrics"`
+
+	// Dashboard serves a web dashboard under / and the JSON API under /api.
+	Dashboard bool `yaml:"dashboard"`
+
+	Health HealthConfig `yaml:"health"`
+}
+
+func (c ServerConfig) Enabled() bool {
+	return c.Address != ""
+}
+
+// HealthConfig configures the /healthz and /readyz endpoints.
+type HealthConfig struct {
+	Enabled bool `yaml:"enabled"`
+
+	// Intervals is the number of request intervals after which dmon is
+	// no longer ready if no run succeeded.
+	Intervals int `yaml:"intervals"`
+}
+
+// RequiredIntervals returns the number of request intervals, which defaults to three.
+func (c HealthConfig) RequiredIntervals() int {
+	if c.Intervals <= 0 {
+		return 3
+	}
+
+	return c.Intervals
+}
+
+// HistoryConfig configures the persistent history of events and their deliveries to the handlers.
+type HistoryConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Type    string `yaml:"type"`
+
+	SQLite struct {
+		Path string `yaml:"path"`
+	} `yaml:"sqlite"`
+
+	// Retention is the number of days that events are kept.
+	Retention int `yaml:"retention_days"`
+}
+
+// SQLitePath returns the path of the database file, which defaults to ./dmon-history.db.
+func (c HistoryConfig) SQLitePath() string {
+	if c.SQLite.Path == "" {
+		return "./dmon-history.db"
+	}
+
+	return c.SQLite.Path
+}
+
+// RetentionDuration returns how long events are kept, which defaults to 30 days.
+func (c HistoryConfig) RetentionDuration() time.Duration {
+	if c.Retention <= 0 {
+		return 30 * 24 * time.Hour
+	}
+
+	return time.Duration(c.Retention) * 24 * time.Hour
+}
+
+// InteractionsConfig configures the endpoints that handle the interactive buttons of messages.
+type InteractionsConfig struct {
 	Slack struct {
-		Token                 string `yaml:"token"`
-		Channel               string `yaml:"channel"`
-		IncludeErrorSection   bool   `yaml:"include_error_section"`
-		IncludeDataflowButton bool   `yaml:"include_dataflow_button"`
+		SigningSecret string `yaml:"signing_secret"`
+		Path          string `yaml:"path"`
 	} `yaml:"slack"`
 }
 
+func (c InteractionsConfig) SlackEnabled() bool {
+	return c.Slack.SigningSecret != ""
+}
+
+func (c InteractionsConfig) SlackPath() string {
+	if c.Slack.Path == "" {
+		return "/slack/actions"
+	}
+
+	return c.Slack.Path
+}
+
+type RedisConfig struct {
+	Address  string `yaml:"address"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	DB       int    `yaml:"db"`
+	Prefix   string `yaml:"prefix"`
+}
+
 func (c Config) MaxTimeoutDuration() time.Duration {
 	return time.Duration(c.Timeout.MaxTimeout) * time.Minute
 }
 
+func (c Config) HardLimitDuration() time.Duration {
+	return time.Duration(c.Timeout.HardLimit) * time.Minute
+}
+
+func (c Config) StreamingHardLimitDuration() time.Duration {
+	return time.Duration(c.Timeout.StreamingHardLimit) * time.Minute
+}
+
 func (c Config) ExpireTimeoutDuration() time.Duration {
 	return time.Duration(c.Timeout.ExpireTimeout) * time.Minute
 }
+
+func (c Config) LeaseDuration() time.Duration {
+	if c.LeaderElection.LeaseDuration <= 0 {
+		return 60 * time.Second
+	}
+
+	return time.Duration(c.LeaderElection.LeaseDuration) * time.Second
+}
+
+func (c RedisConfig) KeyPrefix() string {
+	if c.Prefix == "" {
+		return "dmon:"
+	}
+
+	return c.Prefix
+}
@@ -0,0 +1,13 @@
+package config
+
+// FilterConfig limits the jobs that are monitored. Jobs have to match
+// all of the configured filters, empty filters match every job.
+type FilterConfig struct {
+	Prefix  string            `yaml:"prefix"`
+	Include []string          `yaml:"include"`
+	Exclude []string          `yaml:"exclude"`
+	Labels  map[string]string `yaml:"labels"`
+
+	// JobType is either "batch" or "streaming".
+	JobType string `yaml:"job_type"`
+}
@@ -0,0 +1,180 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// HandlerConfig is a single entry of the handlers list. Besides the type and the
+// name, every entry contains the options of its type, which are decoded by the
+// handler that is registered for the type.
+type HandlerConfig struct {
+	Type string
+	Name string
+
+	// States lists the job states that the handler is notified about,
+	// in addition to failures and timeouts.
+	States []string
+
+	options yaml.Node
+}
+
+// NewHandlerConfig creates a handler config from the options of a handler type.
+func NewHandlerConfig(handlerType string, name string, options interface{}) (HandlerConfig, error) {
+	cfg := HandlerConfig{
+		Type: handlerType,
+		Name: name,
+	}
+
+	err := cfg.options.Encode(options)
+	if err != nil {
+		return HandlerConfig{}, fmt.Errorf("failed to encode options of handler %s: %w", name, err)
+	}
+
+	return cfg, nil
+}
+
+func (h *HandlerConfig) UnmarshalYAML(node *yaml.Node) error {
+	var meta struct {
+		Type   string   `yaml:"type"`
+		Name   string   `yaml:"name"`
+		States []string `yaml:"states"`
+	}
+
+	err := node.Decode(&meta)
+	if err != nil {
+		return err
+	}
+
+	if meta.Type == "" {
+		return fmt.Errorf("handler in line %d is missing a type", node.Line)
+	}
+
+	h.Type = meta.Type
+	h.Name = meta.Name
+	h.States = meta.States
+	h.options = *node
+
+	return nil
+}
+
+// Decode decodes the options of the handler into v.
+func (h HandlerConfig) Decode(v interface{}) error {
+	return h.options.Decode(v)
+}
+
+type SlackConfig struct {
+	Token                 string `yaml:"token"`
+	Channel               string `yaml:"channel"`
+	IncludeErrorSection   bool   `yaml:"include_error_section"`
+	IncludeDataflowButton bool   `yaml:"include_dataflow_button"`
+	IncludeActionButtons  bool   `yaml:"include_action_buttons"`
+	UpdateHeader          bool   `yaml:"update_header"`
+}
+
+type WebhookConfig struct {
+	Url        string            `yaml:"url"`
+	Headers    map[string]string `yaml:"headers"`
+	Secret     string            `yaml:"secret"`
+	Timeout    int               `yaml:"timeout"`
+	MaxRetries int               `yaml:"max_retries"`
+}
+
+func (c WebhookConfig) RequestTimeout() time.Duration {
+	if c.Timeout <= 0 {
+		return 10 * time.Second
+	}
+
+	return time.Duration(c.Timeout) * time.Second
+}
+
+type EmailConfig struct {
+	Host     string   `yaml:"host"`
+	Port     int      `yaml:"port"`
+	Security string   `yaml:"security"`
+	Username string   `yaml:"username"`
+	Password string   `yaml:"password"`
+	From     string   `yaml:"from"`
+	To       []string `yaml:"to"`
+	Cc       []string `yaml:"cc"`
+
+	Subjects struct {
+		Error       string `yaml:"error"`
+		Timeout     string `yaml:"timeout"`
+		StateChange string `yaml:"state_change"`
+		Unhealthy   string `yaml:"unhealthy"`
+		Stuck       string `yaml:"stuck"`
+		FollowUp    string `yaml:"follow_up"`
+		Remediation string `yaml:"remediation"`
+	} `yaml:"subjects"`
+}
+
+type PagerDutyConfig struct {
+	RoutingKey string `yaml:"routing_key"`
+	Timeout    int    `yaml:"timeout"`
+
+	Severities struct {
+		Failure   string `yaml:"failure"`
+		Timeout   string `yaml:"timeout"`
+		Unhealthy string `yaml:"unhealthy"`
+		Stuck     string `yaml:"stuck"`
+	} `yaml:"severities"`
+}
+
+func (c PagerDutyConfig) RequestTimeout() time.Duration {
+	if c.Timeout <= 0 {
+		return 10 * time.Second
+	}
+
+	return time.Duration(c.Timeout) * time.Second
+}
+
+type TeamsConfig struct {
+	WebhookUrl            string `yaml:"webhook_url"`
+	IncludeErrorSection   bool   `yaml:"include_error_section"`
+	IncludeDataflowButton bool   `yaml:"include_dataflow_button"`
+	Timeout               int    `yaml:"timeout"`
+}
+
+func (c TeamsConfig) RequestTimeout() time.Duration {
+	if c.Timeout <= 0 {
+		return 10 * time.Second
+	}
+
+	return time.Duration(c.Timeout) * time.Second
+}
+
+// HandlerConfigs returns all configured handlers. Handlers that are configured
+// through the deprecated top-level sections are appended to the handlers list.
+func (c Config) HandlerConfigs() ([]HandlerConfig, error) {
+	cfgs := append([]HandlerConfig{}, c.Handlers...)
+
+	legacy := []struct {
+		handlerType string
+		enabled     bool
+		options     interface{}
+	}{
+		{"slack", c.Slack.Token != "", c.Slack},
+		{"webhook", c.Webhook.Url != "", c.Webhook},
+		{"email", c.Email.Host != "", c.Email},
+		{"pagerduty", c.PagerDuty.RoutingKey != "", c.PagerDuty},
+		{"teams", c.Teams.WebhookUrl != "", c.Teams},
+	}
+
+	for _, l := range legacy {
+		if !l.enabled {
+			continue
+		}
+
+		cfg, err := NewHandlerConfig(l.handlerType, l.handlerType, l.options)
+		if err != nil {
+			return nil, err
+		}
+
+		cfgs = append(cfgs, cfg)
+	}
+
+	return cfgs, nil
+}
@@ -0,0 +1,109 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yannickalex07/dmon/pkg/config"
+)
+
+func readConfig(t *testing.T, content string) *config.Config {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+
+	err := os.WriteFile(path, []byte(content), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := config.Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return cfg
+}
+
+func TestHandlerConfigDecode(t *testing.T) {
+	// - Arrange
+	cfg := readConfig(t, `
+handlers:
+  - type: slack
+    name: data-team
+    token: token-1
+    channel: data-alerts
+  - type: slack
+    name: platform-team
+    token: token-2
+    channel: platform-alerts
+    include_error_section: true
+`)
+
+	// - Act
+	handlers, err := cfg.HandlerConfigs()
+
+	var first, second config.SlackConfig
+	firstErr := handlers[0].Decode(&first)
+	secondErr := handlers[1].Decode(&second)
+
+	// - Assert
+	assert.Nil(t, err)
+	assert.Nil(t, firstErr)
+	assert.Nil(t, secondErr)
+
+	assert.Equal(t, "slack", handlers[0].Type)
+	assert.Equal(t, "data-team", handlers[0].Name)
+	assert.Equal(t, "data-alerts", first.Channel)
+	assert.False(t, first.IncludeErrorSection)
+
+	assert.Equal(t, "platform-team", handlers[1].Name)
+	assert.Equal(t, "platform-alerts", second.Channel)
+	assert.True(t, second.IncludeErrorSection)
+}
+
+func TestHandlerConfigWithoutType(t *testing.T) {
+	// - Arrange
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	os.WriteFile(path, []byte("handlers:\n  - name: missing-type\n"), 0o600)
+
+	// - Act
+	_, err := config.Read(path)
+
+	// - Assert
+	assert.Error(t, err)
+}
+
+// This test asserts that handlers configured through the deprecated
+// top-level sections are still picked up.
+func TestHandlerConfigsWithLegacySections(t *testing.T) {
+	// - Arrange
+	cfg := readConfig(t, `
+handlers:
+  - type: webhook
+    name: incidents
+    url: https://example.com
+
+slack:
+  token: token
+  channel: alerts
+  include_dataflow_button: true
+`)
+
+	// - Act
+	handlers, err := cfg.HandlerConfigs()
+
+	var slack config.SlackConfig
+	decodeErr := handlers[1].Decode(&slack)
+
+	// - Assert
+	assert.Nil(t, err)
+	assert.Nil(t, decodeErr)
+	assert.Len(t, handlers, 2) // -> unconfigured legacy sections are skipped
+
+	assert.Equal(t, "incidents", handlers[0].Name)
+	assert.Equal(t, "slack", handlers[1].Type)
+	assert.Equal(t, "slack", handlers[1].Name)
+	assert.Equal(t, "alerts", slack.Channel)
+	assert.True(t, slack.IncludeDataflowButton)
+}
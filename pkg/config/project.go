@@ -0,0 +1,63 @@
+package config
+
+import "fmt"
+
+// ProjectConfig is a GCP project whose Dataflow jobs are monitored.
+type ProjectConfig struct {
+	Id string `yaml:"id"`
+
+	// Location and Locations list the locations that are monitored.
+	// If neither is set, the jobs of all locations are monitored.
+	Location  string   `yaml:"location"`
+	Locations []string `yaml:"locations"`
+}
+
+// AllLocations reports if the jobs of all locations of the project should be monitored.
+func (p ProjectConfig) AllLocations() bool {
+	return len(p.LocationList()) == 0
+}
+
+// LocationList returns all configured locations of the project.
+func (p ProjectConfig) LocationList() []string {
+	locations := []string{}
+	if p.Location != "" {
+		locations = append(locations, p.Location)
+	}
+
+	for _, location := range p.Locations {
+		if location != p.Location {
+			locations = append(locations, location)
+		}
+	}
+
+	return locations
+}
+
+// ProjectConfigs returns all configured projects. A project that is configured
+// through the deprecated project section is appended to the projects list.
+func (c Config) ProjectConfigs() ([]ProjectConfig, error) {
+	projects := append([]ProjectConfig{}, c.Projects...)
+
+	if c.Project.Id != "" {
+		projects = append(projects, c.Project)
+	}
+
+	if len(projects) == 0 {
+		return nil, fmt.Errorf("no project is configured")
+	}
+
+	seen := map[string]bool{}
+	for _, p := range projects {
+		if p.Id == "" {
+			return nil, fmt.Errorf("every project requires an id")
+		}
+
+		if seen[p.Id] {
+			return nil, fmt.Errorf("project %s is configured more than once", p.Id)
+		}
+
+		seen[p.Id] = true
+	}
+
+	return projects, nil
+}
@@ -0,0 +1,67 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProjectConfigs(t *testing.T) {
+	// - Arrange
+	cfg := readConfig(t, `
+projects:
+  - id: first-project
+    locations:
+      - europe-west4
+      - us-central1
+  - id: second-project
+project:
+  id: legacy-project
+  location: europe-west1
+`)
+
+	// - Act
+	projects, err := cfg.ProjectConfigs()
+
+	// - Assert
+	assert.Nil(t, err)
+	assert.Len(t, projects, 3)
+
+	assert.Equal(t, "first-project", projects[0].Id)
+	assert.Equal(t, []string{"europe-west4", "us-central1"}, projects[0].LocationList())
+	assert.False(t, projects[0].AllLocations())
+
+	assert.Equal(t, "second-project", projects[1].Id)
+	assert.True(t, projects[1].AllLocations())
+
+	assert.Equal(t, "legacy-project", projects[2].Id)
+	assert.Equal(t, []string{"europe-west1"}, projects[2].LocationList())
+}
+
+func TestProjectConfigsRequiresProject(t *testing.T) {
+	// - Arrange
+	cfg := readConfig(t, `
+request_interval: 1
+`)
+
+	// - Act
+	_, err := cfg.ProjectConfigs()
+
+	// - Assert
+	assert.Error(t, err)
+}
+
+func TestProjectConfigsWithDuplicateProject(t *testing.T) {
+	// - Arrange
+	cfg := readConfig(t, `
+projects:
+  - id: my-project
+  - id: my-project
+`)
+
+	// - Act
+	_, err := cfg.ProjectConfigs()
+
+	// - Assert
+	assert.Error(t, err)
+}
@@ -0,0 +1,24 @@
+package config
+
+type RoutingConfig struct {
+	Routes []RouteConfig `yaml:"routes"`
+
+	// Default lists the handlers that receive events which match no route.
+	Default []string `yaml:"default"`
+}
+
+type RouteConfig struct {
+	Match    MatchConfig `yaml:"match"`
+	Handlers []string    `yaml:"handlers"`
+
+	// Continue controls if the following routes are evaluated after this route matched.
+	Continue bool `yaml:"continue"`
+}
+
+type MatchConfig struct {
+	Name     string            `yaml:"name"`
+	NameGlob string            `yaml:"name_glob"`
+	Labels   map[string]string `yaml:"labels"`
+	Events   []string          `yaml:"events"`
+	JobType  string            `yaml:"job_type"`
+}
@@ -0,0 +1,171 @@
+package dashboard
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yannickalex07/dmon/pkg/model"
+)
+
+// DefaultMaxEvents is the number of recent events that are kept by default.
+const DefaultMaxEvents = 200
+
+// maxErrorLength is the length after which error excerpts are cut off.
+const maxErrorLength = 500
+
+// Event is a notification that was passed to the handlers.
+type Event struct {
+	Time    time.Time `json:"time"`
+	Event   string    `json:"event"`
+	JobId   string    `json:"job_id"`
+	JobName string    `json:"job_name"`
+	Project string    `json:"project"`
+
+	// Detail describes the event, i.e. the new state of a state change.
+	Detail string `json:"detail,omitempty"`
+
+	// Error is set if at least one handler failed to handle the event.
+	Error string `json:"error,omitempty"`
+}
+
+// Job is the status of a job as shown on the dashboard.
+type Job struct {
+	Id                string            `json:"id"`
+	Name              string            `json:"name"`
+	Project           string            `json:"project"`
+	Location          string            `json:"location"`
+	Type              string            `json:"type"`
+	State             string            `json:"state"`
+	Labels            map[string]string `json:"labels,omitempty"`
+	StartTime         time.Time         `json:"start_time"`
+	UpdatedAt         time.Time         `json:"updated_at"`
+	RuntimeSeconds    float64           `json:"runtime_seconds"`
+	MaxRuntimeSeconds float64           `json:"max_runtime_seconds,omitempty"`
+	TimedOut          bool              `json:"timed_out"`
+	LastError         string            `json:"last_error,omitempty"`
+	LastEvent         *Event            `json:"last_event,omitempty"`
+}
+
+// Board keeps the jobs of the last run and the recent events in memory.
+type Board struct {
+	// MaxEvents is the number of recent events that are kept.
+	MaxEvents int
+
+	mu     sync.Mutex
+	jobs   []model.Job
+	errors map[string]string
+	events []Event
+}
+
+func NewBoard(maxEvents int) *Board {
+	return &Board{
+		MaxEvents: maxEvents,
+		errors:    map[string]string{},
+	}
+}
+
+// ObserveJobs replaces the jobs with the jobs of the current run.
+func (b *Board) ObserveJobs(jobs []model.Job) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.jobs = append([]model.Job{}, jobs...)
+
+	// errors of jobs that are no longer listed are dropped
+	listed := map[string]bool{}
+	for _, job := range jobs {
+		listed[job.Id] = true
+	}
+
+	for id := range b.errors {
+		if !listed[id] {
+			delete(b.errors, id)
+		}
+	}
+}
+
+// RecordEvent adds an event, the oldest events are dropped once more than MaxEvents are kept.
+func (b *Board) RecordEvent(event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.events = append(b.events, event)
+	if b.MaxEvents > 0 && len(b.events) > b.MaxEvents {
+		b.events = b.events[len(b.events)-b.MaxEvents:]
+	}
+}
+
+// RecordError keeps an excerpt of the error logs of a failed job.
+func (b *Board) RecordError(jobId string, entries []model.LogEntry) {
+	if len(entries) == 0 {
+		return
+	}
+
+	text := entries[0].Text
+	if len(text) > maxErrorLength {
+		text = text[:maxErrorLength] + "..."
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.errors[jobId] = text
+}
+
+// Jobs returns the jobs of the last run, most recently started jobs first.
+func (b *Board) Jobs() []Job {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	lastEvents := map[string]Event{}
+	for _, event := range b.events {
+		lastEvents[event.JobId] = event
+	}
+
+	jobs := make([]Job, 0, len(b.jobs))
+	for _, job := range b.jobs {
+		runtime := job.Runtime()
+
+		j := Job{
+			Id:                job.Id,
+			Name:              job.Name,
+			Project:           job.Project,
+			Location:          job.Location,
+			Type:              job.Type,
+			State:             job.Status.Status,
+			Labels:            job.Labels,
+			StartTime:         job.StartTime,
+			UpdatedAt:         job.Status.UpdatedAt,
+			RuntimeSeconds:    runtime.Seconds(),
+			MaxRuntimeSeconds: job.MaxRuntime.Seconds(),
+			TimedOut:          job.Status.IsRunning() && job.MaxRuntime > 0 && runtime > job.MaxRuntime,
+			LastError:         b.errors[job.Id],
+		}
+
+		if event, ok := lastEvents[job.Id]; ok {
+			j.LastEvent = &event
+		}
+
+		jobs = append(jobs, j)
+	}
+
+	sort.SliceStable(jobs, func(i, k int) bool {
+		return jobs[i].StartTime.After(jobs[k].StartTime)
+	})
+
+	return jobs
+}
+
+// Events returns the recent events, newest first.
+func (b *Board) Events() []Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	events := make([]Event, 0, len(b.events))
+	for i := len(b.events) - 1; i >= 0; i-- {
+		events = append(events, b.events[i])
+	}
+
+	return events
+}
@@ -0,0 +1,221 @@
+package dashboard_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yannickalex07/dmon/pkg/config"
+	"github.com/yannickalex07/dmon/pkg/dashboard"
+	"github.com/yannickalex07/dmon/pkg/handler"
+	"github.com/yannickalex07/dmon/pkg/model"
+)
+
+// FAKES
+
+type FakeHandler struct {
+	Err error
+}
+
+func (f FakeHandler) HandleError(ctx context.Context, job model.Job, entries []model.LogEntry) error {
+	return f.Err
+}
+
+func (f FakeHandler) HandleTimeout(ctx context.Context, job model.Job) error {
+	return f.Err
+}
+
+func (f FakeHandler) HandleStateChange(ctx context.Context, job model.Job) error {
+	return f.Err
+}
+
+// TESTS
+
+func TestBoardJobs(t *testing.T) {
+	// - Arrange
+	board := dashboard.NewBoard(10)
+	now := time.Now()
+
+	timedOut := model.Job{
+		Id:         "timed-out",
+		Name:       "timed-out-job",
+		Type:       "JOB_TYPE_BATCH",
+		StartTime:  now.Add(-2 * time.Hour),
+		MaxRuntime: time.Hour,
+		Status:     model.Status{Status: "JOB_STATE_RUNNING", UpdatedAt: now},
+	}
+	failed := model.Job{
+		Id:        "failed",
+		Name:      "failed-job",
+		Type:      "JOB_TYPE_BATCH",
+		StartTime: now.Add(-time.Hour),
+		Status:    model.Status{Status: "JOB_STATE_FAILED", UpdatedAt: now},
+	}
+
+	// - Act
+	board.ObserveJobs([]model.Job{timedOut, failed})
+	board.RecordError("failed", []model.LogEntry{{Text: strings.Repeat("x", 600)}})
+	jobs := board.Jobs()
+
+	// - Assert
+	assert.Len(t, jobs, 2)
+
+	assert.Equal(t, "failed", jobs[0].Id)
+	assert.False(t, jobs[0].TimedOut)
+	assert.Equal(t, strings.Repeat("x", 500)+"...", jobs[0].LastError)
+
+	assert.Equal(t, "timed-out", jobs[1].Id)
+	assert.True(t, jobs[1].TimedOut)
+	assert.Equal(t, 3600.0, jobs[1].MaxRuntimeSeconds)
+}
+
+// This test asserts that errors are dropped once their job is no longer listed.
+func TestBoardDropsErrorsOfUnlistedJobs(t *testing.T) {
+	// - Arrange
+	board := dashboard.NewBoard(10)
+	job := model.Job{Id: "failed", Status: model.Status{Status: "JOB_STATE_FAILED"}}
+
+	board.ObserveJobs([]model.Job{job})
+	board.RecordError("failed", []model.LogEntry{{Text: "error"}})
+
+	// - Act
+	board.ObserveJobs([]model.Job{})
+	board.ObserveJobs([]model.Job{job})
+
+	// - Assert
+	assert.Empty(t, board.Jobs()[0].LastError)
+}
+
+func TestBoardKeepsRecentEvents(t *testing.T) {
+	// - Arrange
+	board := dashboard.NewBoard(2)
+
+	// - Act
+	board.RecordEvent(dashboard.Event{JobId: "first"})
+	board.RecordEvent(dashboard.Event{JobId: "second"})
+	board.RecordEvent(dashboard.Event{JobId: "third"})
+
+	// - Assert
+	events := board.Events()
+	assert.Len(t, events, 2)
+	assert.Equal(t, "third", events[0].JobId)
+	assert.Equal(t, "second", events[1].JobId)
+}
+
+func TestHandlerRecordsEvents(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	board := dashboard.NewBoard(10)
+	h := dashboard.Handler{Handler: FakeHandler{Err: errors.New("failed to send")}, Board: board}
+
+	job := model.Job{Id: "id", Name: "job", Status: model.Status{Status: "JOB_STATE_FAILED"}}
+	board.ObserveJobs([]model.Job{job})
+
+	// - Act
+	err := h.HandleError(ctx, job, []model.LogEntry{{Text: "pipeline failed"}})
+	resolveErr := h.HandleResolve(ctx, job)
+
+	// - Assert
+	assert.Error(t, err)
+	assert.Nil(t, resolveErr)
+
+	events := board.Events()
+	assert.Len(t, events, 1)
+	assert.Equal(t, "failure", events[0].Event)
+	assert.Equal(t, "failed to send", events[0].Error)
+
+	jobs := board.Jobs()
+	assert.Equal(t, "pipeline failed", jobs[0].LastError)
+	assert.Equal(t, "failure", jobs[0].LastEvent.Event)
+}
+
+// This test asserts that events which the router delivers to no handler are not recorded.
+func TestHandlerSkipsUndeliveredEvents(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	board := dashboard.NewBoard(10)
+
+	router, err := handler.NewRouter(config.RoutingConfig{}, []handler.NamedHandler{
+		{Name: "fake", Handler: FakeHandler{}, States: []string{"JOB_STATE_DONE"}},
+	})
+	assert.Nil(t, err)
+
+	h := dashboard.Handler{Handler: router, Board: board}
+
+	done := model.Job{Id: "done", Status: model.Status{Status: "JOB_STATE_DONE"}}
+	running := model.Job{Id: "running", Status: model.Status{Status: "JOB_STATE_RUNNING"}}
+
+	// - Act
+	doneErr := h.HandleStateChange(ctx, done)
+	runningErr := h.HandleStateChange(ctx, running)                          // -> not one of the states of the handler
+	unhealthyErr := h.HandleUnhealthy(ctx, running, model.StreamingHealth{}) // -> not supported by the handler
+
+	// - Assert
+	assert.Nil(t, doneErr)
+	assert.Nil(t, runningErr)
+	assert.Nil(t, unhealthyErr)
+
+	events := board.Events()
+	assert.Len(t, events, 1)
+	assert.Equal(t, "done", events[0].JobId)
+	assert.Equal(t, "state_change", events[0].Event)
+}
+
+func TestJobsHandler(t *testing.T) {
+	// - Arrange
+	board := dashboard.NewBoard(10)
+	board.ObserveJobs([]model.Job{{Id: "id", Name: "job", Status: model.Status{Status: "JOB_STATE_RUNNING"}}})
+
+	rec := httptest.NewRecorder()
+
+	// - Act
+	board.JobsHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/api/jobs", nil))
+
+	// - Assert
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	jobs := []map[string]any{}
+	err := json.Unmarshal(rec.Body.Bytes(), &jobs)
+	assert.Nil(t, err)
+	assert.Len(t, jobs, 1)
+	assert.Equal(t, "JOB_STATE_RUNNING", jobs[0]["state"])
+}
+
+func TestEventsHandlerWithoutEvents(t *testing.T) {
+	// - Arrange
+	board := dashboard.NewBoard(10)
+	rec := httptest.NewRecorder()
+
+	// - Act
+	board.EventsHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/api/events", nil))
+
+	// - Assert
+	assert.Equal(t, "[]\n", rec.Body.String())
+}
+
+func TestPageHandler(t *testing.T) {
+	// - Arrange
+	board := dashboard.NewBoard(10)
+	board.ObserveJobs([]model.Job{{Id: "id", Name: "<job>", Status: model.Status{Status: "JOB_STATE_RUNNING"}}})
+	board.RecordEvent(dashboard.Event{Event: "timeout", JobId: "id", JobName: "<job>"})
+
+	rec := httptest.NewRecorder()
+	unknown := httptest.NewRecorder()
+
+	// - Act
+	board.PageHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	board.PageHandler().ServeHTTP(unknown, httptest.NewRequest("GET", "/unknown", nil))
+
+	// - Assert
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "&lt;job&gt;")
+	assert.Contains(t, rec.Body.String(), "timeout")
+	assert.Equal(t, http.StatusNotFound, unknown.Code)
+}
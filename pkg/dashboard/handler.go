@@ -0,0 +1,138 @@
+package dashboard
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/yannickalex07/dmon/pkg/handler"
+	"github.com/yannickalex07/dmon/pkg/model"
+)
+
+// Handler forwards every event to the wrapped handler and records it on the board.
+// Events that a wrapped router does not deliver to any handler are not recorded.
+type Handler struct {
+	Handler handler.Handler
+	Board   *Board
+}
+
+// deliverer is implemented by handlers that only deliver some events, i.e. the router.
+type deliverer interface {
+	Delivers(event handler.Event, job model.Job) bool
+}
+
+func (h Handler) HandleError(ctx context.Context, job model.Job, entries []model.LogEntry) error {
+	h.Board.RecordError(job.Id, entries)
+
+	err := h.Handler.HandleError(ctx, job, entries)
+	h.record(handler.EventFailure, job, job.Retry.String(), err)
+
+	return err
+}
+
+func (h Handler) HandleTimeout(ctx context.Context, job model.Job) error {
+	err := h.Handler.HandleTimeout(ctx, job)
+	h.record(handler.EventTimeout, job, fmt.Sprintf("runtime crossed the limit of %s", job.MaxRuntime), err)
+
+	return err
+}
+
+func (h Handler) HandleResolve(ctx context.Context, job model.Job) error {
+	resolver, ok := h.Handler.(handler.ResolveHandler)
+	if !ok {
+		return nil
+	}
+
+	err := resolver.HandleResolve(ctx, job)
+	h.record(handler.EventResolve, job, job.Status.Status, err)
+
+	return err
+}
+
+func (h Handler) HandleStateChange(ctx context.Context, job model.Job) error {
+	notifier, ok := h.Handler.(handler.StateChangeHandler)
+	if !ok {
+		return nil
+	}
+
+	err := notifier.HandleStateChange(ctx, job)
+	h.record(handler.EventStateChange, job, job.Status.Status, err)
+
+	return err
+}
+
+func (h Handler) HandleUnhealthy(ctx context.Context, job model.Job, health model.StreamingHealth) error {
+	notifier, ok := h.Handler.(handler.UnhealthyHandler)
+	if !ok {
+		return nil
+	}
+
+	err := notifier.HandleUnhealthy(ctx, job, health)
+	detail := fmt.Sprintf("unhealthy for %d consecutive checks", health.ConsecutiveChecks)
+	h.record(handler.EventUnhealthy, job, detail, err)
+
+	return err
+}
+
+func (h Handler) HandleStuck(ctx context.Context, job model.Job, limit time.Duration) error {
+	notifier, ok := h.Handler.(handler.StuckHandler)
+	if !ok {
+		return nil
+	}
+
+	err := notifier.HandleStuck(ctx, job, limit)
+	h.record(handler.EventStuck, job, fmt.Sprintf("in state %s for more than %s", job.Status.Status, limit), err)
+
+	return err
+}
+
+func (h Handler) HandleFollowUp(ctx context.Context, job model.Job) error {
+	notifier, ok := h.Handler.(handler.FollowUpHandler)
+	if !ok {
+		return nil
+	}
+
+	err := notifier.HandleFollowUp(ctx, job)
+	h.record(handler.EventFollowUp, job, job.Status.Status, err)
+
+	return err
+}
+
+func (h Handler) HandleRemediation(ctx context.Context, job model.Job, remediation model.Remediation) error {
+	notifier, ok := h.Handler.(handler.RemediationHandler)
+	if !ok {
+		return nil
+	}
+
+	err := notifier.HandleRemediation(ctx, job, remediation)
+
+	detail := fmt.Sprintf("%s after the hard limit of %s", remediation.Action, remediation.HardLimit)
+	if remediation.DryRun {
+		detail += " (dry run)"
+	}
+
+	h.record(handler.EventRemediation, job, detail, err)
+
+	return err
+}
+
+func (h Handler) record(event handler.Event, job model.Job, detail string, err error) {
+	if d, ok := h.Handler.(deliverer); ok && !d.Delivers(event, job) {
+		return
+	}
+
+	e := Event{
+		Time:    time.Now().UTC(),
+		Event:   string(event),
+		JobId:   job.Id,
+		JobName: job.Name,
+		Project: job.Project,
+		Detail:  detail,
+	}
+
+	if err != nil {
+		e.Error = err.Error()
+	}
+
+	h.Board.RecordEvent(e)
+}
@@ -0,0 +1,75 @@
+package dashboard
+
+import (
+	_ "embed"
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+//go:embed dashboard.html
+var dashboardHTML string
+
+var page = template.Must(template.New("dashboard").Funcs(template.FuncMap{
+	"duration": func(seconds float64) string {
+		return (time.Duration(seconds) * time.Second).String()
+	},
+	"time": func(t time.Time) string {
+		if t.IsZero() {
+			return "-"
+		}
+
+		return t.UTC().Format("2006-01-02 15:04:05 MST")
+	},
+}).Parse(dashboardHTML))
+
+// JobsHandler serves the jobs of the last run as JSON.
+func (b *Board) JobsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, b.Jobs())
+	})
+}
+
+// EventsHandler serves the recent events as JSON.
+func (b *Board) EventsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, b.Events())
+	})
+}
+
+// PageHandler serves the dashboard as HTML page.
+func (b *Board) PageHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+
+		data := struct {
+			Jobs   []Job
+			Events []Event
+		}{
+			Jobs:   b.Jobs(),
+			Events: b.Events(),
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+		err := page.Execute(w, data)
+		if err != nil {
+			log.Errorf("failed to render dashboard: %s", err.Error())
+		}
+	})
+}
+
+func writeJSON(w http.ResponseWriter, body any) {
+	w.Header().Set("Content-Type", "application/json")
+
+	err := json.NewEncoder(w).Encode(body)
+	if err != nil {
+		log.Errorf("failed to write dashboard response: %s", err.Error())
+	}
+}
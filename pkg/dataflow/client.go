@@ -2,17 +2,177 @@ package dataflow
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
+	log "github.com/sirupsen/logrus"
 	"github.com/yannickalex07/dmon/pkg/model"
+	"google.golang.org/api/option"
 )
 
 type Dataflow interface {
 	Jobs(ctx context.Context) ([]model.Job, error)
-	ErrorLogs(ctx context.Context, jobId string) ([]model.LogEntry, error)
+	ErrorLogs(ctx context.Context, job model.Job) ([]model.LogEntry, error)
+	StreamingMetrics(ctx context.Context, job model.Job) (model.StreamingMetrics, error)
+
+	// UpdateState requests a new state for the job, i.e. JOB_STATE_CANCELLED.
+	UpdateState(ctx context.Context, job model.Job, state string) error
+
+	// LaunchTemplate launches the template with the parameters of the job
+	// and returns the id of the new job.
+	LaunchTemplate(ctx context.Context, job model.Job, launch model.TemplateLaunch) (string, error)
 }
 
+// DataflowClient lists the jobs of a single project. If no location is set,
+// the jobs of all locations of the project are listed.
 type DataflowClient struct {
 	Project  string
 	Location string
-	Prefix   string
+
+	// Filter removes all jobs from the listing that should not be monitored.
+	Filter Filter
+
+	// Details caches the details of finished and running jobs, if it is nil
+	// the details are requested on every listing.
+	Details *DetailsCache
+
+	// Options are passed to the Dataflow service, i.e. to use another endpoint in tests.
+	Options []option.ClientOption
+}
+
+// MultiClient combines the jobs of multiple projects and locations.
+type MultiClient struct {
+	Clients []DataflowClient
+}
+
+// PartialError is returned together with the listed jobs if the jobs of some, but not all,
+// clients could not be listed.
+type PartialError struct {
+	// Failed are the clients whose jobs are missing from the listing.
+	Failed []DataflowClient
+	Err    error
+}
+
+func (e *PartialError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PartialError) Unwrap() error {
+	return e.Err
+}
+
+// Jobs combines the jobs of all clients. Clients that fail are skipped, so that a single
+// misconfigured project does not stop the monitoring of all other projects. If only some
+// clients failed, their jobs are returned together with a PartialError.
+func (m MultiClient) Jobs(ctx context.Context) ([]model.Job, error) {
+	var jobs []model.Job
+	var errs []error
+	var failed []DataflowClient
+
+	for _, client := range m.Clients {
+		clientJobs, err := client.Jobs(ctx)
+		if err != nil {
+			wrappedErr := fmt.Errorf("failed to list jobs of project %s in %s: %w", client.Project, client.locationName(), err)
+			log.Error(wrappedErr.Error())
+
+			errs = append(errs, wrappedErr)
+			failed = append(failed, client)
+			continue
+		}
+
+		jobs = append(jobs, clientJobs...)
+	}
+
+	if len(errs) == 0 {
+		return jobs, nil
+	}
+
+	if len(errs) == len(m.Clients) {
+		return nil, errors.Join(errs...)
+	}
+
+	return jobs, &PartialError{Failed: failed, Err: errors.Join(errs...)}
+}
+
+// Check verifies that the Dataflow API can be requested by every client, like a run of
+// the monitor fails if the jobs of a single client are missing.
+func (m MultiClient) Check(ctx context.Context) error {
+	var errs []error
+
+	for _, client := range m.Clients {
+		err := client.Check(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to request project %s in %s: %w", client.Project, client.locationName(), err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// ErrorLogs requests the error logs from the client that is responsible for the project of the job.
+func (m MultiClient) ErrorLogs(ctx context.Context, job model.Job) ([]model.LogEntry, error) {
+	client, err := m.clientFor(job)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.ErrorLogs(ctx, job)
+}
+
+// StreamingMetrics requests the metrics from the client that is responsible for the project of the job.
+func (m MultiClient) StreamingMetrics(ctx context.Context, job model.Job) (model.StreamingMetrics, error) {
+	client, err := m.clientFor(job)
+	if err != nil {
+		return model.StreamingMetrics{}, err
+	}
+
+	return client.StreamingMetrics(ctx, job)
+}
+
+// UpdateState requests the new state from the client that is responsible for the project of the job.
+func (m MultiClient) UpdateState(ctx context.Context, job model.Job, state string) error {
+	client, err := m.clientFor(job)
+	if err != nil {
+		return err
+	}
+
+	return client.UpdateState(ctx, job, state)
+}
+
+// LaunchTemplate launches the template through the client that is responsible for the project of the job.
+func (m MultiClient) LaunchTemplate(ctx context.Context, job model.Job, launch model.TemplateLaunch) (string, error) {
+	client, err := m.clientFor(job)
+	if err != nil {
+		return "", err
+	}
+
+	return client.LaunchTemplate(ctx, job, launch)
+}
+
+func (m MultiClient) clientFor(job model.Job) (DataflowClient, error) {
+	for _, client := range m.Clients {
+		if client.Covers(job) {
+			return client, nil
+		}
+	}
+
+	return DataflowClient{}, fmt.Errorf("no client is configured for project %s and location %s", job.Project, job.Location)
+}
+
+// Covers reports if the job belongs to the project and location of the client.
+func (client DataflowClient) Covers(job model.Job) bool {
+	if client.Project != job.Project {
+		return false
+	}
+
+	return client.Location == "" || client.Location == job.Location
+}
+
+// locationName describes the location of the client for log messages.
+func (client DataflowClient) locationName() string {
+	if client.Location == "" {
+		return "all locations"
+	}
+
+	return "location " + client.Location
 }
@@ -0,0 +1,129 @@
+package dataflow_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yannickalex07/dmon/pkg/dataflow"
+	"google.golang.org/api/option"
+)
+
+// newJobsServer fakes the Dataflow API with a single running job in europe-west1.
+func newJobsServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		job := `{"id": "my-job-id", "name": "my-job", "type": "JOB_TYPE_BATCH", "currentState": "JOB_STATE_RUNNING", "currentStateTime": "2023-06-01T12:00:00Z", "startTime": "2023-06-01T11:00:00Z"}`
+		if strings.HasSuffix(r.URL.Path, "/jobs") {
+			w.Write([]byte(`{"jobs": [` + job + `]}`))
+			return
+		}
+
+		w.Write([]byte(job))
+	}))
+}
+
+func newFailingServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"error": {"code": 403, "message": "permission denied"}}`))
+	}))
+}
+
+func newClient(project string, server *httptest.Server) dataflow.DataflowClient {
+	return dataflow.DataflowClient{
+		Project:  project,
+		Location: "europe-west1",
+		Options:  []option.ClientOption{option.WithEndpoint(server.URL), option.WithoutAuthentication()},
+	}
+}
+
+// This test asserts that the jobs of working clients are still returned if another client fails.
+func TestMultiClientJobsWithFailingClient(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+
+	failingServer := newFailingServer()
+	defer failingServer.Close()
+
+	workingServer := newJobsServer()
+	defer workingServer.Close()
+
+	client := dataflow.MultiClient{Clients: []dataflow.DataflowClient{
+		newClient("failing-project", failingServer),
+		newClient("working-project", workingServer),
+	}}
+
+	// - Act
+	jobs, err := client.Jobs(ctx)
+
+	// - Assert
+	var partialErr *dataflow.PartialError
+	assert.ErrorAs(t, err, &partialErr)
+	assert.Len(t, partialErr.Failed, 1)
+	assert.Equal(t, "failing-project", partialErr.Failed[0].Project)
+
+	assert.Len(t, jobs, 1)
+	assert.Equal(t, "my-job-id", jobs[0].Id)
+	assert.Equal(t, "working-project", jobs[0].Project)
+}
+
+func TestMultiClientJobsWithOnlyFailingClients(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+
+	failingServer := newFailingServer()
+	defer failingServer.Close()
+
+	client := dataflow.MultiClient{Clients: []dataflow.DataflowClient{
+		newClient("first-project", failingServer),
+		newClient("second-project", failingServer),
+	}}
+
+	// - Act
+	jobs, err := client.Jobs(ctx)
+
+	// - Assert
+	var partialErr *dataflow.PartialError
+	assert.Error(t, err)
+	assert.False(t, errors.As(err, &partialErr))
+	assert.Contains(t, err.Error(), "first-project")
+	assert.Contains(t, err.Error(), "second-project")
+	assert.Nil(t, jobs)
+}
+
+// This test asserts that the check fails as soon as a single client can't request the Dataflow API.
+func TestMultiClientCheck(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+
+	failingServer := newFailingServer()
+	defer failingServer.Close()
+
+	workingServer := newJobsServer()
+	defer workingServer.Close()
+
+	working := dataflow.MultiClient{Clients: []dataflow.DataflowClient{
+		newClient("working-project", workingServer),
+	}}
+
+	partlyFailing := dataflow.MultiClient{Clients: []dataflow.DataflowClient{
+		newClient("failing-project", failingServer),
+		newClient("working-project", workingServer),
+	}}
+
+	// - Act
+	workingErr := working.Check(ctx)
+	partlyFailingErr := partlyFailing.Check(ctx)
+
+	// - Assert
+	assert.Nil(t, workingErr)
+	assert.ErrorContains(t, partlyFailingErr, "failed to request project failing-project in location europe-west1")
+	assert.NotContains(t, partlyFailingErr.Error(), "working-project")
+}
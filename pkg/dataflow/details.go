@@ -0,0 +1,88 @@
+package dataflow
+
+import (
+	"sync"
+	"time"
+
+	dataflow "google.golang.org/api/dataflow/v1b3"
+)
+
+// runningDetailsTTL is how long the details of jobs that are still running are cached.
+// Their labels and environment rarely change, so they are only requested again after the
+// ttl or once the state of the job changes.
+const runningDetailsTTL time.Duration = 15 * time.Minute
+
+// DetailsCache keeps the details of jobs that reached a terminal state, since
+// these never change, and the details of running jobs for a short time. This
+// avoids requesting the details of every job again on each listing.
+type DetailsCache struct {
+	mu   sync.Mutex
+	jobs map[string]cachedDetails
+}
+
+type cachedDetails struct {
+	job *dataflow.Job
+
+	// expiresAt is zero for details that are kept as long as the job is listed.
+	expiresAt time.Time
+}
+
+func NewDetailsCache() *DetailsCache {
+	return &DetailsCache{
+		jobs: map[string]cachedDetails{},
+	}
+}
+
+func (c *DetailsCache) get(id string) (*dataflow.Job, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cached, ok := c.jobs[id]
+	if !ok {
+		return nil, false
+	}
+
+	if !cached.expiresAt.IsZero() && time.Now().After(cached.expiresAt) {
+		delete(c.jobs, id)
+		return nil, false
+	}
+
+	return cached.job, true
+}
+
+// store caches the details of the job, a ttl of zero keeps them as long as the job is listed.
+func (c *DetailsCache) store(job *dataflow.Job, ttl time.Duration) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cached := cachedDetails{job: job}
+	if ttl > 0 {
+		cached.expiresAt = time.Now().Add(ttl)
+	}
+
+	c.jobs[job.Id] = cached
+}
+
+// retain removes all jobs that are no longer part of the listing.
+func (c *DetailsCache) retain(ids map[string]bool) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for id := range c.jobs {
+		if !ids[id] {
+			delete(c.jobs, id)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package dataflow
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/yannickalex07/dmon/pkg/config"
+	"github.com/yannickalex07/dmon/pkg/model"
+)
+
+// Filter decides which jobs are monitored. Empty fields match every job.
+type Filter struct {
+	Prefix string
+
+	// A job has to match at least one of the include patterns
+	// and must not match any of the exclude patterns.
+	Include []*regexp.Regexp
+	Exclude []*regexp.Regexp
+
+	Labels map[string]string
+
+	// JobType is either "batch" or "streaming".
+	JobType string
+}
+
+// NewFilter compiles the patterns of the filter config.
+func NewFilter(cfg config.FilterConfig) (Filter, error) {
+	filter := Filter{
+		Prefix:  cfg.Prefix,
+		Labels:  cfg.Labels,
+		JobType: cfg.JobType,
+	}
+
+	switch cfg.JobType {
+	case "", "batch", "streaming":
+	default:
+		return Filter{}, fmt.Errorf("unknown job type %s", cfg.JobType)
+	}
+
+	compile := func(patterns []string) ([]*regexp.Regexp, error) {
+		compiled := make([]*regexp.Regexp, 0, len(patterns))
+		for _, pattern := range patterns {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern %s: %w", pattern, err)
+			}
+
+			compiled = append(compiled, re)
+		}
+
+		return compiled, nil
+	}
+
+	var err error
+	filter.Include, err = compile(cfg.Include)
+	if err != nil {
+		return Filter{}, err
+	}
+
+	filter.Exclude, err = compile(cfg.Exclude)
+	if err != nil {
+		return Filter{}, err
+	}
+
+	return filter, nil
+}
+
+func (f Filter) Matches(job model.Job) bool {
+	if !f.MatchesName(job.Name) {
+		return false
+	}
+
+	for key, value := range f.Labels {
+		if job.Labels[key] != value {
+			return false
+		}
+	}
+
+	return f.MatchesType(job.Type)
+}
+
+// MatchesType only checks the filter for the type of a job, i.e. JOB_TYPE_STREAMING.
+func (f Filter) MatchesType(jobType string) bool {
+	job := model.Job{Type: jobType}
+
+	switch f.JobType {
+	case "batch":
+		return !job.IsStreaming()
+	case "streaming":
+		return job.IsStreaming()
+	}
+
+	return true
+}
+
+// MatchesName only checks the filters for the name of a job.
+func (f Filter) MatchesName(name string) bool {
+	if f.Prefix != "" && !strings.HasPrefix(name, f.Prefix) {
+		return false
+	}
+
+	if len(f.Include) > 0 {
+		included := false
+		for _, re := range f.Include {
+			if re.MatchString(name) {
+				included = true
+				break
+			}
+		}
+
+		if !included {
+			return false
+		}
+	}
+
+	for _, re := range f.Exclude {
+		if re.MatchString(name) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,64 @@
+package dataflow_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yannickalex07/dmon/pkg/config"
+	"github.com/yannickalex07/dmon/pkg/dataflow"
+	"github.com/yannickalex07/dmon/pkg/model"
+)
+
+func TestFilterMatches(t *testing.T) {
+	// - Arrange
+	filter, err := dataflow.NewFilter(config.FilterConfig{
+		Prefix:  "etl-",
+		Include: []string{"-daily$", "-hourly$"},
+		Exclude: []string{"^etl-test-"},
+		Labels:  map[string]string{"team": "data"},
+		JobType: "batch",
+	})
+	assert.Nil(t, err)
+
+	newJob := func(name string, jobType string, labels map[string]string) model.Job {
+		return model.Job{Name: name, Type: jobType, Labels: labels}
+	}
+
+	dataLabels := map[string]string{"team": "data"}
+
+	// - Act & Assert
+	assert.True(t, filter.Matches(newJob("etl-orders-daily", "JOB_TYPE_BATCH", dataLabels)))
+	assert.True(t, filter.Matches(newJob("etl-orders-hourly", "JOB_TYPE_BATCH", dataLabels)))
+
+	assert.False(t, filter.Matches(newJob("orders-daily", "JOB_TYPE_BATCH", dataLabels)))              // missing prefix
+	assert.False(t, filter.Matches(newJob("etl-orders-weekly", "JOB_TYPE_BATCH", dataLabels)))         // not included
+	assert.False(t, filter.Matches(newJob("etl-test-orders-daily", "JOB_TYPE_BATCH", dataLabels)))     // excluded
+	assert.False(t, filter.Matches(newJob("etl-orders-daily", "JOB_TYPE_BATCH", map[string]string{}))) // missing label
+	assert.False(t, filter.Matches(newJob("etl-orders-daily", "JOB_TYPE_STREAMING", dataLabels)))      // wrong type
+}
+
+func TestEmptyFilterMatchesEveryJob(t *testing.T) {
+	// - Arrange
+	filter, err := dataflow.NewFilter(config.FilterConfig{})
+	assert.Nil(t, err)
+
+	// - Act
+	matches := filter.Matches(model.Job{Name: "any-job", Type: "JOB_TYPE_STREAMING"})
+
+	// - Assert
+	assert.True(t, matches)
+}
+
+func TestNewFilterWithInvalidConfig(t *testing.T) {
+	// - Arrange
+	invalidPattern := config.FilterConfig{Include: []string{"("}}
+	invalidType := config.FilterConfig{JobType: "unknown"}
+
+	// - Act
+	_, patternErr := dataflow.NewFilter(invalidPattern)
+	_, typeErr := dataflow.NewFilter(invalidType)
+
+	// - Assert
+	assert.Error(t, patternErr)
+	assert.Error(t, typeErr)
+}
@@ -3,72 +3,202 @@ package dataflow
 import (
 	"context"
 	"fmt"
-	"strings"
+	"time"
 
+	log "github.com/sirupsen/logrus"
 	"github.com/yannickalex07/dmon/pkg/model"
 	"github.com/yannickalex07/dmon/pkg/util"
 	dataflow "google.golang.org/api/dataflow/v1b3"
 )
 
+// Labels that Dataflow sets for jobs that were started from a Google provided template.
+const (
+	templateNameLabel    string = "goog-dataflow-provided-template-name"
+	templateTypeLabel    string = "goog-dataflow-provided-template-type"
+	templateVersionLabel string = "goog-dataflow-provided-template-version"
+)
+
 func (client DataflowClient) Jobs(ctx context.Context) ([]model.Job, error) {
 	if ctx == nil {
 		ctx = context.Background()
 	}
 
 	// create service and request
-	service, err := dataflow.NewService(ctx)
+	service, err := dataflow.NewService(ctx, client.Options...)
 	if err != nil {
 		return nil, err
 	}
 
-	jobService := dataflow.NewProjectsLocationsJobsService(service)
-	req := jobService.List(client.Project, client.Location)
-
 	// request list of jobs
 	var jobs []model.Job
-	err = req.Pages(ctx, func(res *dataflow.ListJobsResponse) error {
-		for _, job := range res.Jobs {
-
-			// check if the name matches a prefix as long as one is required
-			if client.Prefix != "" {
-				matches := strings.HasPrefix(job.Name, client.Prefix)
-				if !matches {
-					continue
-				}
+	listed := map[string]bool{}
+	collect := func(res *dataflow.ListJobsResponse) error {
+		for _, summary := range res.Jobs {
+			listed[summary.Id] = true
+
+			// skip jobs that should not be monitored, before requesting their details
+			if !client.Filter.MatchesName(summary.Name) || !client.Filter.MatchesType(summary.Type) {
+				continue
 			}
 
-			// parse timestamps
-			startTime, err := util.ParseTimestamp(job.StartTime)
+			// jobs of the aggregated list carry their own location
+			location := summary.Location
+			if location == "" {
+				location = client.Location
+			}
+
+			// the listing only contains a summary of the job, labels
+			// and the environment are only part of the job details.
+			// If the details are unavailable, the job is still monitored without them.
+			job, err := client.details(ctx, service, location, summary)
 			if err != nil {
-				return fmt.Errorf("failed to parse state time with: %w", err)
+				log.Warnf("Failed to request details of job %s, monitoring it without labels: %s", summary.Id, err.Error())
+				job = summary
 			}
 
-			statusTime, err := util.ParseTimestamp(job.CurrentStateTime)
+			j, err := ParseJob(job, client.Project, location)
 			if err != nil {
-				return fmt.Errorf("failed to parse current status time with %w", err)
+				return err
 			}
 
-			// add job
-			j := model.Job{
-				Id:   job.Id,
-				Name: job.Name,
-				Type: job.Type,
-				Status: model.Status{
-					Status:    job.CurrentState,
-					UpdatedAt: statusTime,
-				},
-				StartTime: startTime,
+			if !client.Filter.Matches(j) {
+				continue
 			}
 
 			jobs = append(jobs, j)
 		}
 
 		return nil
-	})
+	}
+
+	if client.Location == "" {
+		req := dataflow.NewProjectsJobsService(service).Aggregated(client.Project)
+		err = req.Pages(ctx, collect)
+	} else {
+		req := dataflow.NewProjectsLocationsJobsService(service).List(client.Project, client.Location)
+		err = req.Pages(ctx, collect)
+	}
 
 	if err != nil {
 		return nil, err
 	}
 
+	client.Details.retain(listed)
+
 	return jobs, nil
 }
+
+// Check lists a single job of the project to verify that the Dataflow API can be requested,
+// without requesting the details of any job.
+func (client DataflowClient) Check(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	service, err := dataflow.NewService(ctx, client.Options...)
+	if err != nil {
+		return err
+	}
+
+	if client.Location == "" {
+		_, err = dataflow.NewProjectsJobsService(service).Aggregated(client.Project).PageSize(1).Context(ctx).Do()
+	} else {
+		_, err = dataflow.NewProjectsLocationsJobsService(service).List(client.Project, client.Location).PageSize(1).Context(ctx).Do()
+	}
+
+	return err
+}
+
+// details requests the details of the job. The details of finished jobs are cached
+// and the details of running jobs for a short time, as long as the client has a cache.
+// Every request counts against the read quota of the Dataflow API.
+func (client DataflowClient) details(ctx context.Context, service *dataflow.Service, location string, summary *dataflow.Job) (*dataflow.Job, error) {
+	cached, ok := client.Details.get(summary.Id)
+	if ok && cached.CurrentState == summary.CurrentState {
+		return cached, nil
+	}
+
+	req := dataflow.NewProjectsLocationsJobsService(service).Get(client.Project, location, summary.Id)
+	job, err := req.View("JOB_VIEW_DESCRIPTION").Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := runningDetailsTTL
+	status := model.Status{Status: job.CurrentState}
+	if status.IsTerminal() {
+		ttl = 0
+	}
+
+	client.Details.store(job, ttl)
+
+	return job, nil
+}
+
+// ParseJob converts a job of the Dataflow API into a job of the model.
+func ParseJob(job *dataflow.Job, project string, location string) (model.Job, error) {
+	// parse timestamps
+	startTime, err := util.ParseTimestamp(job.StartTime)
+	if err != nil {
+		return model.Job{}, fmt.Errorf("failed to parse state time with: %w", err)
+	}
+
+	statusTime, err := util.ParseTimestamp(job.CurrentStateTime)
+	if err != nil {
+		return model.Job{}, fmt.Errorf("failed to parse current status time with %w", err)
+	}
+
+	// the create time is missing for some older jobs, so it is optional
+	var createTime time.Time
+	if job.CreateTime != "" {
+		createTime, err = util.ParseTimestamp(job.CreateTime)
+		if err != nil {
+			return model.Job{}, fmt.Errorf("failed to parse create time with %w", err)
+		}
+	}
+
+	j := model.Job{
+		Id:   job.Id,
+		Name: job.Name,
+		Type: job.Type,
+		Status: model.Status{
+			Status:    job.CurrentState,
+			UpdatedAt: statusTime,
+		},
+		StartTime:       startTime,
+		Labels:          job.Labels,
+		Project:         project,
+		Location:        location,
+		CreateTime:      createTime,
+		ReplaceJobId:    job.ReplaceJobId,
+		ReplacedByJobId: job.ReplacedByJobId,
+		Template: model.Template{
+			Name:    job.Labels[templateNameLabel],
+			Type:    job.Labels[templateTypeLabel],
+			Version: job.Labels[templateVersionLabel],
+		},
+	}
+
+	if job.JobMetadata != nil && job.JobMetadata.SdkVersion != nil {
+		j.SdkVersion = model.SdkVersion{
+			Version:       job.JobMetadata.SdkVersion.Version,
+			DisplayName:   job.JobMetadata.SdkVersion.VersionDisplayName,
+			SupportStatus: job.JobMetadata.SdkVersion.SdkSupportStatus,
+		}
+	}
+
+	if env := job.Environment; env != nil {
+		j.Environment = model.Environment{
+			WorkerRegion:   env.WorkerRegion,
+			WorkerZone:     env.WorkerZone,
+			ServiceAccount: env.ServiceAccountEmail,
+		}
+
+		if len(env.WorkerPools) > 0 {
+			j.Environment.MachineType = env.WorkerPools[0].MachineType
+			j.Environment.NumWorkers = env.WorkerPools[0].NumWorkers
+		}
+	}
+
+	return j, nil
+}
@@ -0,0 +1,199 @@
+package dataflow_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yannickalex07/dmon/pkg/config"
+	"github.com/yannickalex07/dmon/pkg/dataflow"
+	"github.com/yannickalex07/dmon/pkg/model"
+	api "google.golang.org/api/dataflow/v1b3"
+)
+
+func TestParseJob(t *testing.T) {
+	// - Arrange
+	job := &api.Job{
+		Id:               "my-job-id",
+		Name:             "my-job",
+		Type:             "JOB_TYPE_BATCH",
+		CurrentState:     "JOB_STATE_UPDATED",
+		CurrentStateTime: "2023-06-01T12:00:00Z",
+		CreateTime:       "2023-06-01T10:59:00Z",
+		StartTime:        "2023-06-01T11:00:00Z",
+		ReplacedByJobId:  "my-new-job-id",
+		Labels: map[string]string{
+			"team":                                 "data",
+			"goog-dataflow-provided-template-name": "pubsub_to_bigquery",
+			"goog-dataflow-provided-template-type": "flex",
+		},
+		JobMetadata: &api.JobMetadata{
+			SdkVersion: &api.SdkVersion{
+				Version:            "2.48.0",
+				VersionDisplayName: "Apache Beam SDK for Java",
+				SdkSupportStatus:   "STABLE",
+			},
+		},
+		Environment: &api.Environment{
+			WorkerRegion:        "europe-west4",
+			ServiceAccountEmail: "dataflow@my-project.iam.gserviceaccount.com",
+			WorkerPools: []*api.WorkerPool{
+				{MachineType: "n1-standard-4", NumWorkers: 3},
+			},
+		},
+	}
+
+	// - Act
+	j, err := dataflow.ParseJob(job, "my-project", "europe-west4")
+
+	// - Assert
+	assert.Nil(t, err)
+
+	assert.Equal(t, "my-job-id", j.Id)
+	assert.Equal(t, "my-project", j.Project)
+	assert.Equal(t, "europe-west4", j.Location)
+	assert.Equal(t, time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC), j.Status.UpdatedAt)
+	assert.Equal(t, time.Date(2023, 6, 1, 10, 59, 0, 0, time.UTC), j.CreateTime)
+	assert.Equal(t, "data", j.Labels["team"])
+	assert.True(t, j.WasReplaced())
+	assert.Equal(t, "my-new-job-id", j.ReplacedByJobId)
+	assert.Equal(t, model.SdkVersion{Version: "2.48.0", DisplayName: "Apache Beam SDK for Java", SupportStatus: "STABLE"}, j.SdkVersion)
+	assert.Equal(t, model.Template{Name: "pubsub_to_bigquery", Type: "flex"}, j.Template)
+	assert.Equal(t, model.Environment{
+		WorkerRegion:   "europe-west4",
+		ServiceAccount: "dataflow@my-project.iam.gserviceaccount.com",
+		MachineType:    "n1-standard-4",
+		NumWorkers:     3,
+	}, j.Environment)
+}
+
+func TestParseJobWithInvalidTimestamp(t *testing.T) {
+	// - Arrange
+	job := &api.Job{
+		Id:               "my-job-id",
+		StartTime:        "yesterday",
+		CurrentStateTime: "2023-06-01T12:00:00Z",
+	}
+
+	// - Act
+	_, err := dataflow.ParseJob(job, "my-project", "europe-west4")
+
+	// - Assert
+	assert.Error(t, err)
+}
+
+// This test asserts that jobs are still listed if their details can't be requested.
+func TestJobsWithFailingDetails(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if !strings.HasSuffix(r.URL.Path, "/jobs") {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"error": {"code": 500, "message": "internal error"}}`))
+			return
+		}
+
+		w.Write([]byte(`{"jobs": [{"id": "my-job-id", "name": "my-job", "type": "JOB_TYPE_BATCH", "currentState": "JOB_STATE_RUNNING", "currentStateTime": "2023-06-01T12:00:00Z", "startTime": "2023-06-01T11:00:00Z"}]}`))
+	}))
+	defer server.Close()
+
+	client := newClient("my-project", server)
+
+	// - Act
+	jobs, err := client.Jobs(ctx)
+
+	// - Assert
+	assert.Nil(t, err)
+	assert.Len(t, jobs, 1)
+	assert.Equal(t, "my-job-id", jobs[0].Id)
+	assert.Equal(t, "JOB_STATE_RUNNING", jobs[0].Status.Status)
+	assert.Empty(t, jobs[0].Labels)
+}
+
+// This test asserts that the details of jobs are only requested if their summary matches the filter.
+func TestJobsSkipsDetailsOfFilteredJobs(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+
+	detailRequests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if !strings.HasSuffix(r.URL.Path, "/jobs") {
+			detailRequests++
+			w.Write([]byte(`{"id": "batch-job-id", "name": "batch-job", "type": "JOB_TYPE_BATCH", "currentState": "JOB_STATE_RUNNING", "currentStateTime": "2023-06-01T12:00:00Z", "startTime": "2023-06-01T11:00:00Z"}`))
+			return
+		}
+
+		w.Write([]byte(`{"jobs": [
+			{"id": "batch-job-id", "name": "batch-job", "type": "JOB_TYPE_BATCH", "currentState": "JOB_STATE_RUNNING", "currentStateTime": "2023-06-01T12:00:00Z", "startTime": "2023-06-01T11:00:00Z"},
+			{"id": "streaming-job-id", "name": "streaming-job", "type": "JOB_TYPE_STREAMING", "currentState": "JOB_STATE_RUNNING", "currentStateTime": "2023-06-01T12:00:00Z", "startTime": "2023-06-01T11:00:00Z"},
+			{"id": "excluded-job-id", "name": "excluded-job", "type": "JOB_TYPE_BATCH", "currentState": "JOB_STATE_RUNNING", "currentStateTime": "2023-06-01T12:00:00Z", "startTime": "2023-06-01T11:00:00Z"}
+		]}`))
+	}))
+	defer server.Close()
+
+	filter, err := dataflow.NewFilter(config.FilterConfig{JobType: "batch", Exclude: []string{"^excluded"}})
+	assert.Nil(t, err)
+
+	client := newClient("my-project", server)
+	client.Filter = filter
+
+	// - Act
+	jobs, err := client.Jobs(ctx)
+
+	// - Assert
+	assert.Nil(t, err)
+	assert.Len(t, jobs, 1)
+	assert.Equal(t, "batch-job-id", jobs[0].Id)
+	assert.Equal(t, 1, detailRequests)
+}
+
+// This test asserts that the details of running jobs are cached until their state changes.
+func TestJobsCachesDetailsOfRunningJobs(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+
+	state := "JOB_STATE_RUNNING"
+	detailRequests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		job := `{"id": "my-job-id", "name": "my-job", "type": "JOB_TYPE_BATCH", "currentState": "` + state + `", "currentStateTime": "2023-06-01T12:00:00Z", "startTime": "2023-06-01T11:00:00Z"}`
+		if !strings.HasSuffix(r.URL.Path, "/jobs") {
+			detailRequests++
+			w.Write([]byte(job))
+			return
+		}
+
+		w.Write([]byte(`{"jobs": [` + job + `]}`))
+	}))
+	defer server.Close()
+
+	client := newClient("my-project", server)
+	client.Details = dataflow.NewDetailsCache()
+
+	// - Act
+	_, firstErr := client.Jobs(ctx)
+	_, cachedErr := client.Jobs(ctx)
+	cachedRequests := detailRequests
+
+	state = "JOB_STATE_DONE"
+	jobs, changedErr := client.Jobs(ctx)
+
+	// - Assert
+	assert.Nil(t, firstErr)
+	assert.Nil(t, cachedErr)
+	assert.Nil(t, changedErr)
+
+	assert.Equal(t, 1, cachedRequests)
+	assert.Equal(t, 2, detailRequests) // -> requested again after the state changed
+	assert.Equal(t, "JOB_STATE_DONE", jobs[0].Status.Status)
+}
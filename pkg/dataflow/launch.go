@@ -0,0 +1,162 @@
+package dataflow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/yannickalex07/dmon/pkg/model"
+	dataflow "google.golang.org/api/dataflow/v1b3"
+)
+
+// templateLocationOption is the pipeline option that contains the path of a classic template.
+const templateLocationOption string = "templateLocation"
+
+func (client DataflowClient) LaunchTemplate(ctx context.Context, job model.Job, launch model.TemplateLaunch) (string, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	// create service and request
+	service, err := dataflow.NewService(ctx, client.Options...)
+	if err != nil {
+		return "", err
+	}
+
+	location := job.Location
+	if location == "" {
+		location = client.Location
+	}
+
+	// the pipeline options are only part of the full job
+	req := dataflow.NewProjectsLocationsJobsService(service).Get(client.Project, location, job.Id)
+	details, err := req.View("JOB_VIEW_ALL").Context(ctx).Do()
+	if err != nil {
+		return "", fmt.Errorf("failed to request options of job %s with: %w", job.Id, err)
+	}
+
+	options, err := PipelineOptions(details)
+	if err != nil {
+		return "", err
+	}
+
+	path := launch.Path
+	if path == "" && !launch.Flex {
+		path = options[templateLocationOption]
+	}
+
+	if path == "" {
+		return "", fmt.Errorf("the template path of job %s is unknown", job.Id)
+	}
+
+	// classic templates reject unknown parameters, so only the declared ones are passed
+	names := launch.Parameters
+	if len(names) == 0 && !launch.Flex {
+		names, err = templateParameterNames(ctx, service, client.Project, location, path)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	parameters := TemplateParameters(options, names)
+
+	if launch.Flex {
+		request := &dataflow.LaunchFlexTemplateRequest{
+			LaunchParameter: &dataflow.LaunchFlexTemplateParameter{
+				JobName:              job.Name,
+				ContainerSpecGcsPath: path,
+				Parameters:           parameters,
+				Environment:          &dataflow.FlexTemplateRuntimeEnvironment{AdditionalUserLabels: launch.Labels},
+			},
+		}
+
+		res, err := dataflow.NewProjectsLocationsFlexTemplatesService(service).Launch(client.Project, location, request).Context(ctx).Do()
+		if err != nil {
+			return "", fmt.Errorf("failed to launch flex template %s with: %w", path, err)
+		}
+
+		return launchedJobId(res.Job)
+	}
+
+	request := &dataflow.LaunchTemplateParameters{
+		JobName:     job.Name,
+		Parameters:  parameters,
+		Environment: &dataflow.RuntimeEnvironment{AdditionalUserLabels: launch.Labels},
+	}
+
+	res, err := dataflow.NewProjectsLocationsTemplatesService(service).Launch(client.Project, location, request).GcsPath(path).Context(ctx).Do()
+	if err != nil {
+		return "", fmt.Errorf("failed to launch template %s with: %w", path, err)
+	}
+
+	return launchedJobId(res.Job)
+}
+
+// PipelineOptions returns the pipeline options that the job was started with.
+// Options that are no plain values, i.e. lists, are skipped.
+func PipelineOptions(job *dataflow.Job) (map[string]string, error) {
+	options := map[string]string{}
+	if job.Environment == nil || len(job.Environment.SdkPipelineOptions) == 0 {
+		return options, nil
+	}
+
+	var sdkOptions struct {
+		Options map[string]interface{} `json:"options"`
+	}
+
+	err := json.Unmarshal(job.Environment.SdkPipelineOptions, &sdkOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse pipeline options of job %s with: %w", job.Id, err)
+	}
+
+	for key, value := range sdkOptions.Options {
+		switch v := value.(type) {
+		case string:
+			options[key] = v
+		case float64, bool:
+			options[key] = fmt.Sprint(v)
+		}
+	}
+
+	return options, nil
+}
+
+// TemplateParameters selects the options with the given names.
+func TemplateParameters(options map[string]string, names []string) map[string]string {
+	parameters := map[string]string{}
+	for _, name := range names {
+		if value, ok := options[name]; ok {
+			parameters[name] = value
+		}
+	}
+
+	return parameters
+}
+
+// templateParameterNames returns the names of the parameters that are declared
+// in the metadata of a classic template.
+func templateParameterNames(ctx context.Context, service *dataflow.Service, project string, location string, path string) ([]string, error) {
+	res, err := dataflow.NewProjectsLocationsTemplatesService(service).Get(project, location).GcsPath(path).View("METADATA_ONLY").Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to request metadata of template %s with: %w", path, err)
+	}
+
+	names := []string{}
+	if res.Metadata == nil {
+		return names, nil
+	}
+
+	for _, parameter := range res.Metadata.Parameters {
+		names = append(names, parameter.Name)
+	}
+
+	return names, nil
+}
+
+func launchedJobId(job *dataflow.Job) (string, error) {
+	if job == nil || job.Id == "" {
+		return "", fmt.Errorf("the launch did not return a job")
+	}
+
+	return job.Id, nil
+}
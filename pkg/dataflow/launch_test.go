@@ -0,0 +1,107 @@
+package dataflow_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yannickalex07/dmon/pkg/dataflow"
+	"github.com/yannickalex07/dmon/pkg/model"
+	dataflowApi "google.golang.org/api/dataflow/v1b3"
+	"google.golang.org/api/option"
+)
+
+func TestPipelineOptions(t *testing.T) {
+	// - Arrange
+	job := &dataflowApi.Job{
+		Environment: &dataflowApi.Environment{
+			SdkPipelineOptions: []byte(`{"options": {"inputFile": "gs://bucket/input", "batchSize": 100, "dryRun": false, "experiments": ["a", "b"]}}`),
+		},
+	}
+
+	// - Act
+	options, err := dataflow.PipelineOptions(job)
+
+	// - Assert
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{
+		"inputFile": "gs://bucket/input",
+		"batchSize": "100",
+		"dryRun":    "false",
+	}, options)
+}
+
+func TestTemplateParameters(t *testing.T) {
+	// - Arrange
+	options := map[string]string{
+		"inputFile": "gs://bucket/input",
+		"output":    "dataset.table",
+		"project":   "my-project",
+		"jobName":   "my-job",
+	}
+
+	// - Act
+	none := dataflow.TemplateParameters(options, nil)
+	selected := dataflow.TemplateParameters(options, []string{"output", "missing"})
+
+	// - Assert
+	assert.Empty(t, none)
+	assert.Equal(t, map[string]string{"output": "dataset.table"}, selected)
+}
+
+func TestLaunchTemplate(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+
+	var launchPath, gcsPath string
+	var launched dataflowApi.LaunchTemplateParameters
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/templates:get") {
+			w.Write([]byte(`{"metadata": {"name": "my-template", "parameters": [{"name": "inputFile"}, {"name": "output"}]}}`))
+			return
+		}
+
+		if r.Method == http.MethodGet {
+			w.Write([]byte(`{"id": "my-job-id", "environment": {"sdkPipelineOptions": {"options": {"inputFile": "gs://bucket/input", "unknownOption": "value", "project": "my-project"}}}}`))
+			return
+		}
+
+		launchPath = r.URL.Path
+		gcsPath = r.URL.Query().Get("gcsPath")
+		json.NewDecoder(r.Body).Decode(&launched)
+
+		w.Write([]byte(`{"job": {"id": "my-new-job-id"}}`))
+	}))
+	defer server.Close()
+
+	client := dataflow.DataflowClient{
+		Project: "my-project",
+		Options: []option.ClientOption{option.WithEndpoint(server.URL), option.WithoutAuthentication()},
+	}
+
+	job := model.Job{Id: "my-job-id", Name: "my-job", Location: "europe-west1"}
+	launch := model.TemplateLaunch{
+		Path:   "gs://bucket/templates/my-template",
+		Labels: map[string]string{"dmon-retry-of": "my-job-id"},
+	}
+
+	// - Act
+	id, err := client.LaunchTemplate(ctx, job, launch)
+
+	// - Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "my-new-job-id", id)
+
+	assert.Equal(t, "/v1b3/projects/my-project/locations/europe-west1/templates:launch", launchPath)
+	assert.Equal(t, "gs://bucket/templates/my-template", gcsPath)
+	assert.Equal(t, "my-job", launched.JobName)
+	assert.Equal(t, map[string]string{"inputFile": "gs://bucket/input"}, launched.Parameters)
+	assert.Equal(t, map[string]string{"dmon-retry-of": "my-job-id"}, launched.Environment.AdditionalUserLabels)
+}
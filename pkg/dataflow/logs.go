@@ -9,19 +9,26 @@ import (
 	dataflow "google.golang.org/api/dataflow/v1b3"
 )
 
-func (client DataflowClient) ErrorLogs(ctx context.Context, jobId string) ([]model.LogEntry, error) {
+func (client DataflowClient) ErrorLogs(ctx context.Context, job model.Job) ([]model.LogEntry, error) {
 	if ctx == nil {
 		ctx = context.Background()
 	}
 
 	// create service and request
-	service, err := dataflow.NewService(ctx)
+	service, err := dataflow.NewService(ctx, client.Options...)
 	if err != nil {
 		return nil, err
 	}
 
 	jobService := dataflow.NewProjectsLocationsJobsMessagesService(service)
-	req := jobService.List(client.Project, client.Location, jobId)
+	// the location of the job is required to request its messages,
+	// which is only known from the job when listing all locations.
+	location := job.Location
+	if location == "" {
+		location = client.Location
+	}
+
+	req := jobService.List(client.Project, location, job.Id)
 
 	// request list of jobs
 	var entries []model.LogEntry
@@ -0,0 +1,90 @@
+package dataflow
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yannickalex07/dmon/pkg/model"
+	dataflow "google.golang.org/api/dataflow/v1b3"
+)
+
+func (client DataflowClient) StreamingMetrics(ctx context.Context, job model.Job) (model.StreamingMetrics, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	// create service and request
+	service, err := dataflow.NewService(ctx, client.Options...)
+	if err != nil {
+		return model.StreamingMetrics{}, err
+	}
+
+	location := job.Location
+	if location == "" {
+		location = client.Location
+	}
+
+	req := dataflow.NewProjectsLocationsJobsService(service).GetMetrics(client.Project, location, job.Id)
+	res, err := req.Context(ctx).Do()
+	if err != nil {
+		return model.StreamingMetrics{}, err
+	}
+
+	return ParseStreamingMetrics(res), nil
+}
+
+// ParseStreamingMetrics extracts the data watermark age and the system lag from the metrics
+// of a job. Both metrics are reported in seconds and can be reported for multiple stages,
+// in which case the largest value is used.
+func ParseStreamingMetrics(metrics *dataflow.JobMetrics) model.StreamingMetrics {
+	result := model.StreamingMetrics{}
+	if metrics == nil {
+		return result
+	}
+
+	for _, metric := range metrics.Metrics {
+		if metric == nil || metric.Name == nil {
+			continue
+		}
+
+		seconds, ok := scalarValue(metric.Scalar)
+		if !ok {
+			continue
+		}
+
+		value := time.Duration(seconds * float64(time.Second))
+
+		// metric names are reported in different styles, i.e. system_lag or SystemLag
+		name := strings.ToLower(strings.ReplaceAll(metric.Name.Name, "_", ""))
+		switch {
+		case strings.HasSuffix(name, "datawatermarkage"):
+			if value > result.DataWatermarkAge {
+				result.DataWatermarkAge = value
+			}
+		case strings.HasSuffix(name, "systemlag"):
+			if value > result.SystemLag {
+				result.SystemLag = value
+			}
+		}
+	}
+
+	return result
+}
+
+func scalarValue(scalar interface{}) (float64, bool) {
+	switch v := scalar.(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	}
+
+	return 0, false
+}
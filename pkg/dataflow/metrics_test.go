@@ -0,0 +1,30 @@
+package dataflow_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yannickalex07/dmon/pkg/dataflow"
+	api "google.golang.org/api/dataflow/v1b3"
+)
+
+func TestParseStreamingMetrics(t *testing.T) {
+	// - Arrange
+	metrics := &api.JobMetrics{
+		Metrics: []*api.MetricUpdate{
+			{Name: &api.MetricStructuredName{Name: "data_watermark_age"}, Scalar: float64(120)},
+			{Name: &api.MetricStructuredName{Name: "DataWatermarkAge"}, Scalar: float64(300)}, // larger value of another stage
+			{Name: &api.MetricStructuredName{Name: "system_lag"}, Scalar: "45"},
+			{Name: &api.MetricStructuredName{Name: "ElementCount"}, Scalar: float64(1000)},
+			{Name: &api.MetricStructuredName{Name: "system_lag"}}, // no scalar value
+		},
+	}
+
+	// - Act
+	result := dataflow.ParseStreamingMetrics(metrics)
+
+	// - Assert
+	assert.Equal(t, 5*time.Minute, result.DataWatermarkAge)
+	assert.Equal(t, 45*time.Second, result.SystemLag)
+}
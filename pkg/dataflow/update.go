@@ -0,0 +1,45 @@
+package dataflow
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/yannickalex07/dmon/pkg/model"
+	dataflow "google.golang.org/api/dataflow/v1b3"
+)
+
+// States that can be requested for a running job.
+const (
+	StateCancelled string = "JOB_STATE_CANCELLED"
+	StateDrained   string = "JOB_STATE_DRAINED"
+)
+
+func (client DataflowClient) UpdateState(ctx context.Context, job model.Job, state string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if state != StateCancelled && state != StateDrained {
+		return fmt.Errorf("the state of a job can not be updated to %s", state)
+	}
+
+	// create service and request
+	service, err := dataflow.NewService(ctx, client.Options...)
+	if err != nil {
+		return err
+	}
+
+	location := job.Location
+	if location == "" {
+		location = client.Location
+	}
+
+	update := &dataflow.Job{RequestedState: state}
+	req := dataflow.NewProjectsLocationsJobsService(service).Update(client.Project, location, job.Id, update)
+	_, err = req.Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("failed to update state of job %s to %s: %w", job.Id, state, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package dataflow_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yannickalex07/dmon/pkg/dataflow"
+	"github.com/yannickalex07/dmon/pkg/model"
+	"google.golang.org/api/option"
+)
+
+func TestUpdateState(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+
+	var method, path, requestedState string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			RequestedState string `json:"requestedState"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+
+		method = r.Method
+		path = r.URL.Path
+		requestedState = body.RequestedState
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": "my-job-id"}`))
+	}))
+	defer server.Close()
+
+	client := dataflow.DataflowClient{
+		Project: "my-project",
+		Options: []option.ClientOption{option.WithEndpoint(server.URL), option.WithoutAuthentication()},
+	}
+
+	job := model.Job{Id: "my-job-id", Location: "europe-west1"}
+
+	// - Act
+	err := client.UpdateState(ctx, job, dataflow.StateDrained)
+
+	// - Assert
+	assert.Nil(t, err)
+	assert.Equal(t, http.MethodPut, method)
+	assert.Equal(t, "/v1b3/projects/my-project/locations/europe-west1/jobs/my-job-id", path)
+	assert.Equal(t, "JOB_STATE_DRAINED", requestedState)
+}
+
+func TestUpdateStateWithInvalidState(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	client := dataflow.DataflowClient{Project: "my-project"}
+
+	// - Act
+	err := client.UpdateState(ctx, model.Job{Id: "my-job-id"}, "JOB_STATE_RUNNING")
+
+	// - Assert
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,351 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	htmltemplate "html/template"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/yannickalex07/dmon/pkg/model"
+)
+
+const (
+	EmailSecurityNone     string = "none"
+	EmailSecuritySTARTTLS string = "starttls"
+	EmailSecurityTLS      string = "tls"
+)
+
+const (
+	defaultEmailErrorSubject       string = "❌ Dataflow job {{ .Job.Name }} failed"
+	defaultEmailTimeoutSubject     string = "⚠️ Dataflow job {{ .Job.Name }} timed out"
+	defaultEmailStateChangeSubject string = "Dataflow job {{ .Job.Name }} changed its state to {{ .Job.Status.Status }}"
+	defaultEmailUnhealthySubject   string = "🐢 Dataflow job {{ .Job.Name }} is unhealthy"
+	defaultEmailStuckSubject       string = "⏳ Dataflow job {{ .Job.Name }} is stuck in {{ .Job.Status.Status }}"
+	defaultEmailFollowUpSubject    string = "Dataflow job {{ .Job.Name }} ended with state {{ .Job.Status.Status }} after a timeout"
+	defaultEmailRemediationSubject string = "🛑 Dataflow job {{ .Job.Name }} crossed its hard limit"
+)
+
+var emailTextTemplate = template.Must(template.New("text").Parse(`{{ .Title }}
+
+{{ .Info }}
+{{ if .ErrorMessage }}
+Error Message:
+{{ .ErrorMessage }}
+{{ end }}{{ range .Details }}
+{{ . }}{{ end }}
+Open in Dataflow UI: {{ .ConsoleUrl }}
+`))
+
+var emailHtmlTemplate = htmltemplate.Must(htmltemplate.New("html").Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: sans-serif;">
+<h2>{{ .Title }}</h2>
+<p>{{ .Info }}</p>
+{{ if .ErrorMessage }}<p>Error Message:</p>
+<pre style="background: #f4f4f4; padding: 8px;">{{ .ErrorMessage }}</pre>
+{{ end }}{{ if .Details }}<p style="color: #666666;">{{ range $i, $d := .Details }}{{ if $i }}<br>{{ end }}{{ $d }}{{ end }}</p>
+{{ end }}<p><a href="{{ .ConsoleUrl }}">Open in Dataflow UI</a></p>
+</body>
+</html>
+`))
+
+// emailContent is the data that is rendered into the subject and body templates.
+type emailContent struct {
+	Job          model.Job
+	Title        string
+	Info         string
+	ErrorMessage string
+	Details      []string
+	ConsoleUrl   string
+}
+
+type EmailHandler struct {
+	Host string
+	Port int
+
+	// Security is either EmailSecurityNone, EmailSecuritySTARTTLS or EmailSecurityTLS.
+	Security string
+
+	// Username and Password are used for PLAIN authentication, no authentication
+	// happens if the username is empty.
+	Username string
+	Password string
+
+	From string
+	To   []string
+	Cc   []string
+
+	// ErrorSubject, TimeoutSubject and StateChangeSubject are templates for the subject
+	// that receive the job as `.Job`. Defaults are used if they are empty.
+	ErrorSubject       string
+	TimeoutSubject     string
+	StateChangeSubject string
+	UnhealthySubject   string
+	StuckSubject       string
+	FollowUpSubject    string
+	RemediationSubject string
+
+	GCPConfig GCPConfig
+}
+
+func (e EmailHandler) HandleError(ctx context.Context, job model.Job, entries []model.LogEntry) error {
+	content := emailContent{
+		Job:        job,
+		Title:      "❌ Job Failed",
+		Info:       fmt.Sprintf("The job %s with id %s failed at %s!", job.Name, job.Id, job.Status.UpdatedAt.Format(time.RFC1123)),
+		Details:    jobDetails(job),
+		ConsoleUrl: e.GCPConfig.ConsoleUrl(job),
+	}
+
+	if msg, ok := lastErrorLine(entries); ok {
+		content.ErrorMessage = msg
+	} else {
+		content.ErrorMessage = "Failed to fetch log entries."
+	}
+
+	return e.send(ctx, e.subjectTemplate(e.ErrorSubject, defaultEmailErrorSubject), content)
+}
+
+func (e EmailHandler) HandleTimeout(ctx context.Context, job model.Job) error {
+	limit := ""
+	if job.MaxRuntime > 0 {
+		limit = fmt.Sprintf(" of %s", job.MaxRuntime)
+	}
+
+	content := emailContent{
+		Job:        job,
+		Title:      "⚠️ Job Timeout",
+		Info:       fmt.Sprintf("The job %s with id %s crossed the maximum timeout limit%s with a runtime of %s.", job.Name, job.Id, limit, job.Runtime().Round(time.Second)),
+		Details:    jobDetails(job),
+		ConsoleUrl: e.GCPConfig.ConsoleUrl(job),
+	}
+
+	return e.send(ctx, e.subjectTemplate(e.TimeoutSubject, defaultEmailTimeoutSubject), content)
+}
+
+func (e EmailHandler) HandleStateChange(ctx context.Context, job model.Job) error {
+	content := emailContent{
+		Job:        job,
+		Title:      stateTitle(job),
+		Info:       fmt.Sprintf("The job %s with id %s changed its state to %s at %s.", job.Name, job.Id, job.Status.Status, job.Status.UpdatedAt.Format(time.RFC1123)),
+		Details:    jobDetails(job),
+		ConsoleUrl: e.GCPConfig.ConsoleUrl(job),
+	}
+
+	return e.send(ctx, e.subjectTemplate(e.StateChangeSubject, defaultEmailStateChangeSubject), content)
+}
+
+func (e EmailHandler) HandleUnhealthy(ctx context.Context, job model.Job, health model.StreamingHealth) error {
+	content := emailContent{
+		Job:        job,
+		Title:      "🐢 Streaming Job Unhealthy",
+		Info:       fmt.Sprintf("The streaming job %s with id %s was unhealthy for %d consecutive checks.", job.Name, job.Id, health.ConsecutiveChecks),
+		Details:    append(healthDetails(health), jobDetails(job)...),
+		ConsoleUrl: e.GCPConfig.ConsoleUrl(job),
+	}
+
+	return e.send(ctx, e.subjectTemplate(e.UnhealthySubject, defaultEmailUnhealthySubject), content)
+}
+
+func (e EmailHandler) HandleStuck(ctx context.Context, job model.Job, limit time.Duration) error {
+	content := emailContent{
+		Job:        job,
+		Title:      "⏳ Job Stuck",
+		Info:       fmt.Sprintf("The job %s with id %s is in the state %s since %s, which is longer than the allowed %s.", job.Name, job.Id, job.Status.Status, job.Status.UpdatedAt.Format(time.RFC1123), limit),
+		Details:    jobDetails(job),
+		ConsoleUrl: e.GCPConfig.ConsoleUrl(job),
+	}
+
+	return e.send(ctx, e.subjectTemplate(e.StuckSubject, defaultEmailStuckSubject), content)
+}
+
+func (e EmailHandler) HandleFollowUp(ctx context.Context, job model.Job) error {
+	content := emailContent{
+		Job:        job,
+		Title:      stateTitle(job),
+		Info:       fmt.Sprintf("The job %s with id %s that timed out before %s.", job.Name, job.Id, followUpText(job)),
+		ConsoleUrl: e.GCPConfig.ConsoleUrl(job),
+	}
+
+	return e.send(ctx, e.subjectTemplate(e.FollowUpSubject, defaultEmailFollowUpSubject), content)
+}
+
+func (e EmailHandler) HandleRemediation(ctx context.Context, job model.Job, remediation model.Remediation) error {
+	content := emailContent{
+		Job:        job,
+		Title:      remediationTitle(remediation),
+		Info:       fmt.Sprintf("The job %s with id %s %s after a runtime of %s.", job.Name, job.Id, remediationText(remediation), job.Runtime().Round(time.Second)),
+		Details:    jobDetails(job),
+		ConsoleUrl: e.GCPConfig.ConsoleUrl(job),
+	}
+
+	return e.send(ctx, e.subjectTemplate(e.RemediationSubject, defaultEmailRemediationSubject), content)
+}
+
+func (e EmailHandler) subjectTemplate(subject string, fallback string) string {
+	if subject == "" {
+		return fallback
+	}
+
+	return subject
+}
+
+func (e EmailHandler) send(ctx context.Context, subjectTemplate string, content emailContent) error {
+	msg, err := e.createMessage(subjectTemplate, content)
+	if err != nil {
+		return fmt.Errorf("failed to create email: %w", err)
+	}
+
+	client, err := e.dial(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect to mail server: %w", err)
+	}
+	defer client.Close()
+
+	if e.Username != "" {
+		auth := smtp.PlainAuth("", e.Username, e.Password, e.Host)
+		err = client.Auth(auth)
+		if err != nil {
+			return fmt.Errorf("failed to authenticate with mail server: %w", err)
+		}
+	}
+
+	err = client.Mail(e.From)
+	if err != nil {
+		return fmt.Errorf("failed to set sender: %w", err)
+	}
+
+	for _, rcpt := range append(append([]string{}, e.To...), e.Cc...) {
+		err = client.Rcpt(rcpt)
+		if err != nil {
+			return fmt.Errorf("failed to add recipient %s: %w", rcpt, err)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("failed to start sending email: %w", err)
+	}
+
+	_, err = w.Write(msg)
+	if err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	err = w.Close()
+	if err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	return client.Quit()
+}
+
+func (e EmailHandler) dial(ctx context.Context) (*smtp.Client, error) {
+	addr := net.JoinHostPort(e.Host, strconv.Itoa(e.Port))
+	tlsConfig := &tls.Config{ServerName: e.Host}
+
+	var conn net.Conn
+	var err error
+
+	dialer := &net.Dialer{Timeout: 30 * time.Second}
+	if e.Security == EmailSecurityTLS {
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: tlsConfig}
+		conn, err = tlsDialer.DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := smtp.NewClient(conn, e.Host)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if e.Security == EmailSecuritySTARTTLS {
+		err = client.StartTLS(tlsConfig)
+		if err != nil {
+			client.Close()
+			return nil, err
+		}
+	}
+
+	return client, nil
+}
+
+func (e EmailHandler) createMessage(subjectTemplate string, content emailContent) ([]byte, error) {
+	subjectTmpl, err := template.New("subject").Parse(subjectTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse subject template: %w", err)
+	}
+
+	var subject strings.Builder
+	err = subjectTmpl.Execute(&subject, content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render subject: %w", err)
+	}
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	// headers
+	headers := []string{
+		"From: " + e.From,
+		"To: " + strings.Join(e.To, ", "),
+	}
+
+	if len(e.Cc) > 0 {
+		headers = append(headers, "Cc: "+strings.Join(e.Cc, ", "))
+	}
+
+	headers = append(headers,
+		"Subject: "+mime.QEncoding.Encode("utf-8", subject.String()),
+		"Date: "+time.Now().Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		fmt.Sprintf("Content-Type: multipart/alternative; boundary=%q", writer.Boundary()),
+	)
+
+	buf.WriteString(strings.Join(headers, "\r\n") + "\r\n\r\n")
+
+	// plaintext part, which comes first as clients prefer the last part they understand
+	textPart, err := writer.CreatePart(textproto.MIMEHeader{"Content-Type": {"text/plain; charset=utf-8"}})
+	if err != nil {
+		return nil, err
+	}
+
+	err = emailTextTemplate.Execute(textPart, content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render text body: %w", err)
+	}
+
+	// html part
+	htmlPart, err := writer.CreatePart(textproto.MIMEHeader{"Content-Type": {"text/html; charset=utf-8"}})
+	if err != nil {
+		return nil, err
+	}
+
+	err = emailHtmlTemplate.Execute(htmlPart, content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render html body: %w", err)
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,215 @@
+package handler_test
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yannickalex07/dmon/pkg/handler"
+	"github.com/yannickalex07/dmon/pkg/model"
+)
+
+// FAKES
+
+type ReceivedEmail struct {
+	From       string
+	Recipients []string
+	Data       string
+}
+
+// FakeSMTPServer implements the bare minimum of SMTP to receive emails without encryption and authentication.
+type FakeSMTPServer struct {
+	listener net.Listener
+
+	mu     sync.Mutex
+	emails []ReceivedEmail
+}
+
+func newFakeSMTPServer(t *testing.T) *FakeSMTPServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := &FakeSMTPServer{listener: listener}
+	go server.serve()
+
+	t.Cleanup(func() { listener.Close() })
+
+	return server
+}
+
+func (s *FakeSMTPServer) Port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *FakeSMTPServer) Emails() []ReceivedEmail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.emails
+}
+
+func (s *FakeSMTPServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		go s.handle(conn)
+	}
+}
+
+func (s *FakeSMTPServer) handle(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	email := ReceivedEmail{}
+	reply("220 localhost ESMTP")
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		line = strings.TrimSpace(line)
+		command := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+
+		switch command {
+		case "EHLO", "HELO":
+			reply("250 localhost")
+		case "MAIL":
+			email.From = strings.Trim(strings.TrimPrefix(line, "MAIL FROM:"), "<>")
+			reply("250 OK")
+		case "RCPT":
+			email.Recipients = append(email.Recipients, strings.Trim(strings.TrimPrefix(line, "RCPT TO:"), "<>"))
+			reply("250 OK")
+		case "DATA":
+			reply("354 Start mail input")
+
+			var data strings.Builder
+			for {
+				dataLine, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+
+				if dataLine == ".\r\n" {
+					break
+				}
+
+				data.WriteString(dataLine)
+			}
+
+			email.Data = data.String()
+
+			s.mu.Lock()
+			s.emails = append(s.emails, email)
+			s.mu.Unlock()
+
+			reply("250 OK")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+// TESTS
+
+func TestEmailHandlerHandleError(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	server := newFakeSMTPServer(t)
+
+	job := newJob()
+	entries := []model.LogEntry{
+		{Text: "Traceback (most recent call last):\n  File \"main.py\"\nValueError: invalid input\n", Time: time.Now()},
+	}
+
+	h := handler.EmailHandler{
+		Host:     "127.0.0.1",
+		Port:     server.Port(),
+		Security: handler.EmailSecurityNone,
+		From:     "dmon@example.com",
+		To:       []string{"team@example.com"},
+		Cc:       []string{"lead@example.com"},
+		GCPConfig: handler.GCPConfig{
+			Id:       "my-project",
+			Location: "europe-west4",
+		},
+	}
+
+	// - Act
+	err := h.HandleError(ctx, job, entries)
+
+	// - Assert
+	assert.Nil(t, err)
+	assert.Len(t, server.Emails(), 1)
+
+	email := server.Emails()[0]
+	assert.Equal(t, "dmon@example.com", email.From)
+	assert.Equal(t, []string{"team@example.com", "lead@example.com"}, email.Recipients)
+
+	assert.Contains(t, email.Data, "Cc: lead@example.com")
+	assert.Contains(t, email.Data, "Content-Type: text/plain")
+	assert.Contains(t, email.Data, "Content-Type: text/html")
+	assert.Contains(t, email.Data, "ValueError: invalid input")
+	assert.NotContains(t, email.Data, "Traceback") // -> only the last line should be included
+	assert.Contains(t, email.Data, "https://console.cloud.google.com/dataflow/jobs/europe-west4/my-job-id")
+}
+
+func TestEmailHandlerHandleTimeoutWithSubjectTemplate(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	server := newFakeSMTPServer(t)
+
+	h := handler.EmailHandler{
+		Host:           "127.0.0.1",
+		Port:           server.Port(),
+		Security:       handler.EmailSecurityNone,
+		From:           "dmon@example.com",
+		To:             []string{"team@example.com"},
+		TimeoutSubject: "Timeout of {{ .Job.Id }}",
+	}
+
+	// - Act
+	err := h.HandleTimeout(ctx, newJob())
+
+	// - Assert
+	assert.Nil(t, err)
+	assert.Len(t, server.Emails(), 1)
+	assert.Contains(t, server.Emails()[0].Data, "Subject: Timeout of my-job-id")
+}
+
+func TestEmailHandlerFailsWithInvalidSubjectTemplate(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	server := newFakeSMTPServer(t)
+
+	h := handler.EmailHandler{
+		Host:         "127.0.0.1",
+		Port:         server.Port(),
+		From:         "dmon@example.com",
+		To:           []string{"team@example.com"},
+		ErrorSubject: "{{ .Job.Id",
+	}
+
+	// - Act
+	err := h.HandleError(ctx, newJob(), nil)
+
+	// - Assert
+	assert.Error(t, err)
+	assert.Empty(t, server.Emails())
+}
@@ -2,11 +2,236 @@ package handler
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/yannickalex07/dmon/pkg/model"
 )
 
+// Event is the type of event that a handler is notified about.
+type Event string
+
+const (
+	EventFailure     Event = "failure"
+	EventTimeout     Event = "timeout"
+	EventResolve     Event = "resolve"
+	EventStateChange Event = "state_change"
+	EventUnhealthy   Event = "unhealthy"
+	EventStuck       Event = "stuck"
+	EventFollowUp    Event = "follow_up"
+	EventRemediation Event = "remediation"
+)
+
+const dataflowUrl string = "https://console.cloud.google.com/dataflow/jobs/%s/%s?project=%s&authuser=1&hl=en"
+
+// GCPConfig is the project and location that is used for jobs that
+// do not carry their own project and location.
+type GCPConfig struct {
+	Id       string
+	Location string
+}
+
+// ConsoleUrl returns the link to the given job in the Dataflow UI.
+func (c GCPConfig) ConsoleUrl(job model.Job) string {
+	return fmt.Sprintf(dataflowUrl, c.LocationOf(job), job.Id, c.ProjectOf(job))
+}
+
+// ProjectOf returns the project of the job, falling back to the configured project.
+func (c GCPConfig) ProjectOf(job model.Job) string {
+	if job.Project != "" {
+		return job.Project
+	}
+
+	return c.Id
+}
+
+// LocationOf returns the location of the job, falling back to the configured location.
+func (c GCPConfig) LocationOf(job model.Job) string {
+	if job.Location != "" {
+		return job.Location
+	}
+
+	return c.Location
+}
+
+// stateTitle returns a short, human readable title for a new state of a job.
+func stateTitle(job model.Job) string {
+	switch job.Status.Status {
+	case "JOB_STATE_DONE":
+		return "✅ Job Succeeded"
+	case "JOB_STATE_FAILED":
+		return "❌ Job Failed"
+	case "JOB_STATE_CANCELLED":
+		return "🛑 Job Cancelled"
+	case "JOB_STATE_DRAINED":
+		return "🛑 Job Drained"
+	case "JOB_STATE_UPDATED":
+		return "🔄 Job Updated"
+	case "JOB_STATE_RUNNING":
+		return "▶️ Job Running"
+	default:
+		return "ℹ️ Job State Changed"
+	}
+}
+
+// jobDetails returns short facts about the job that help to understand a notification,
+// i.e. the SDK version or if the job was replaced by an update.
+func jobDetails(job model.Job) []string {
+	details := []string{}
+
+	if sdk := job.SdkVersion.String(); sdk != "" {
+		switch job.SdkVersion.SupportStatus {
+		case "DEPRECATED", "UNSUPPORTED":
+			sdk = fmt.Sprintf("%s (%s)", sdk, strings.ToLower(job.SdkVersion.SupportStatus))
+		}
+
+		details = append(details, "SDK: "+sdk)
+	}
+
+	if job.IsTemplate() {
+		details = append(details, strings.TrimSpace("Template: "+job.Template.Name+" "+job.Template.Version))
+	}
+
+	if job.IsReplacement() {
+		details = append(details, "Replaces job "+job.ReplaceJobId)
+	}
+
+	if job.WasReplaced() {
+		details = append(details, "Replaced by job "+job.ReplacedByJobId)
+	}
+
+	if retry := job.Retry.String(); retry != "" {
+		details = append(details, "Retry: "+retry)
+	}
+
+	return details
+}
+
+// followUpText describes how a job that timed out before ended, i.e.
+// "finished after 2h13m0s, 1h13m0s over the limit of 1h0m0s".
+func followUpText(job model.Job) string {
+	var verb string
+	switch {
+	case job.Status.IsDone():
+		verb = "finished"
+	case job.Status.IsFailed():
+		verb = "failed"
+	case job.Status.IsCanceled():
+		verb = "was cancelled"
+	default:
+		verb = fmt.Sprintf("ended with state %s", job.Status.Status)
+	}
+
+	runtime := job.Runtime().Round(time.Second)
+	over := (job.Runtime() - job.MaxRuntime).Round(time.Second)
+
+	return fmt.Sprintf("%s after %s, %s over the limit of %s", verb, runtime, over, job.MaxRuntime)
+}
+
+// remediationTitle returns the title of a remediation notification.
+func remediationTitle(remediation model.Remediation) string {
+	action := "Cancelled"
+	if remediation.Action == model.RemediationDrain {
+		action = "Drained"
+	}
+
+	switch {
+	case remediation.DryRun:
+		return fmt.Sprintf("🧪 Job Would Be %s (Dry Run)", action)
+	case remediation.Failed():
+		return fmt.Sprintf("⚠️ Job Could Not Be %s", action)
+	default:
+		return fmt.Sprintf("🛑 Job %s by dmon", action)
+	}
+}
+
+// remediationText describes the action that was taken, i.e.
+// "crossed its hard limit of 4h0m0s, dmon requested its cancellation".
+func remediationText(remediation model.Remediation) string {
+	action := "cancellation"
+	if remediation.Action == model.RemediationDrain {
+		action = "drain"
+	}
+
+	limit := fmt.Sprintf("crossed its hard limit of %s", remediation.HardLimit)
+
+	switch {
+	case remediation.DryRun:
+		return fmt.Sprintf("%s, dmon would have requested its %s but runs in dry-run mode", limit, action)
+	case remediation.Failed():
+		return fmt.Sprintf("%s, but dmon failed to request its %s: %s", limit, action, remediation.Error)
+	default:
+		return fmt.Sprintf("%s, dmon requested its %s", limit, action)
+	}
+}
+
+// healthDetails describes the metrics of an unhealthy streaming job together with their limits.
+func healthDetails(health model.StreamingHealth) []string {
+	details := []string{}
+
+	if health.MaxDataWatermarkAge > 0 {
+		details = append(details, fmt.Sprintf("Data watermark age: %s (limit %s)", health.Metrics.DataWatermarkAge.Round(time.Second), health.MaxDataWatermarkAge))
+	}
+
+	if health.MaxSystemLag > 0 {
+		details = append(details, fmt.Sprintf("System lag: %s (limit %s)", health.Metrics.SystemLag.Round(time.Second), health.MaxSystemLag))
+	}
+
+	return details
+}
+
+// lastErrorLine extracts the actual error message from the latest error entry,
+// which is the last line of the (often multiline) log text.
+func lastErrorLine(entries []model.LogEntry) (string, bool) {
+	if len(entries) == 0 {
+		return "", false
+	}
+
+	cleaned := strings.TrimSpace(entries[0].Text)
+	msgParts := strings.Split(cleaned, "\n")
+
+	return msgParts[len(msgParts)-1], true
+}
+
 type Handler interface {
 	HandleError(ctx context.Context, job model.Job, entries []model.LogEntry) error
 	HandleTimeout(ctx context.Context, job model.Job) error
 }
+
+// StateChangeHandler is implemented by handlers that can notify about any new state
+// of a job, i.e. successful completions or cancellations. Which states are forwarded
+// to a handler is configured per handler.
+type StateChangeHandler interface {
+	HandleStateChange(ctx context.Context, job model.Job) error
+}
+
+// UnhealthyHandler is implemented by handlers that can notify about running
+// streaming jobs whose metrics exceed the configured thresholds.
+type UnhealthyHandler interface {
+	HandleUnhealthy(ctx context.Context, job model.Job, health model.StreamingHealth) error
+}
+
+// StuckHandler is implemented by handlers that can notify about jobs
+// that stay in a state like JOB_STATE_QUEUED for longer than the given limit.
+type StuckHandler interface {
+	HandleStuck(ctx context.Context, job model.Job, limit time.Duration) error
+}
+
+// FollowUpHandler is implemented by handlers that can follow up on a previous timeout,
+// once the job reached a terminal state. The limit of the timeout is set as MaxRuntime of the job.
+type FollowUpHandler interface {
+	HandleFollowUp(ctx context.Context, job model.Job) error
+}
+
+// RemediationHandler is implemented by handlers that can notify about the actions
+// that were taken for jobs which exceeded their hard limit.
+type RemediationHandler interface {
+	HandleRemediation(ctx context.Context, job model.Job, remediation model.Remediation) error
+}
+
+// ResolveHandler is implemented by handlers that want to be notified when a job
+// finished successfully or was canceled, i.e. to resolve previously raised alerts.
+type ResolveHandler interface {
+	HandleResolve(ctx context.Context, job model.Job) error
+}
@@ -0,0 +1,33 @@
+package handler_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yannickalex07/dmon/pkg/handler"
+	"github.com/yannickalex07/dmon/pkg/model"
+)
+
+func TestGCPConfigConsoleUrlUsesProjectOfJob(t *testing.T) {
+	// - Arrange
+	cfg := handler.GCPConfig{Id: "fallback-project", Location: "europe-west4"}
+	job := model.Job{Id: "my-job-id", Project: "other-project", Location: "us-central1"}
+
+	// - Act
+	url := cfg.ConsoleUrl(job)
+
+	// - Assert
+	assert.Equal(t, "https://console.cloud.google.com/dataflow/jobs/us-central1/my-job-id?project=other-project&authuser=1&hl=en", url)
+}
+
+func TestGCPConfigConsoleUrlFallsBackToConfig(t *testing.T) {
+	// - Arrange
+	cfg := handler.GCPConfig{Id: "fallback-project", Location: "europe-west4"}
+	job := model.Job{Id: "my-job-id"}
+
+	// - Act
+	url := cfg.ConsoleUrl(job)
+
+	// - Assert
+	assert.Equal(t, "https://console.cloud.google.com/dataflow/jobs/europe-west4/my-job-id?project=fallback-project&authuser=1&hl=en", url)
+}
@@ -0,0 +1,282 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yannickalex07/dmon/pkg/model"
+	"github.com/yannickalex07/dmon/pkg/storage"
+)
+
+const pagerDutyEventsUrl string = "https://events.pagerduty.com/v2/enqueue"
+
+// pagerDutyIncidentRetention is how long a triggered incident is remembered for its resolve.
+const pagerDutyIncidentRetention = 7 * 24 * time.Hour
+
+// PagerDutySeverities are the severities that the Events API accepts.
+var PagerDutySeverities = []string{"critical", "error", "warning", "info"}
+
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Client      string            `json:"client,omitempty"`
+	ClientUrl   string            `json:"client_url,omitempty"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+	Links       []pagerDutyLink   `json:"links,omitempty"`
+}
+
+type pagerDutyPayload struct {
+	Summary       string            `json:"summary"`
+	Source        string            `json:"source"`
+	Severity      string            `json:"severity"`
+	Timestamp     string            `json:"timestamp,omitempty"`
+	Component     string            `json:"component,omitempty"`
+	Group         string            `json:"group,omitempty"`
+	Class         string            `json:"class,omitempty"`
+	CustomDetails map[string]string `json:"custom_details,omitempty"`
+}
+
+type pagerDutyLink struct {
+	Href string `json:"href"`
+	Text string `json:"text"`
+}
+
+// PagerDutyHandler triggers incidents through the PagerDuty Events API v2.
+// All events of a job share the same dedup key, so that a failure after a timeout
+// ends up in the same incident and the incident is resolved once the job finishes.
+type PagerDutyHandler struct {
+	RoutingKey string
+
+	// The severities are one of critical, error, warning or info.
+	FailureSeverity   string
+	TimeoutSeverity   string
+	UnhealthySeverity string
+	StuckSeverity     string
+
+	// Url of the Events API, defaults to the official endpoint.
+	Url string
+
+	// Timeout limits the duration of a single request.
+	Timeout time.Duration
+
+	GCPConfig GCPConfig
+
+	// Store is used to remember the triggered incidents, so that only these are resolved.
+	// Without a store every finished job is resolved.
+	Store storage.ValueStorage
+}
+
+func (p PagerDutyHandler) HandleError(ctx context.Context, job model.Job, entries []model.LogEntry) error {
+	details := p.details(job)
+	if msg, ok := lastErrorLine(entries); ok {
+		details["error"] = msg
+	}
+
+	event := p.triggerEvent(job, "failure", p.severity(p.FailureSeverity, "error"), details)
+	event.Payload.Summary = fmt.Sprintf("Dataflow job %s failed", job.Name)
+	event.Payload.Timestamp = job.Status.UpdatedAt.Format(time.RFC3339)
+
+	return p.trigger(ctx, job, event)
+}
+
+func (p PagerDutyHandler) HandleTimeout(ctx context.Context, job model.Job) error {
+	event := p.triggerEvent(job, "timeout", p.severity(p.TimeoutSeverity, "warning"), p.details(job))
+	event.Payload.Summary = fmt.Sprintf("Dataflow job %s crossed the maximum timeout with a runtime of %s", job.Name, job.Runtime().Round(time.Second))
+	if job.MaxRuntime > 0 {
+		event.Payload.Summary = fmt.Sprintf("Dataflow job %s crossed the maximum timeout of %s with a runtime of %s", job.Name, job.MaxRuntime, job.Runtime().Round(time.Second))
+	}
+	event.Payload.Timestamp = time.Now().UTC().Format(time.RFC3339)
+
+	return p.trigger(ctx, job, event)
+}
+
+func (p PagerDutyHandler) HandleUnhealthy(ctx context.Context, job model.Job, health model.StreamingHealth) error {
+	details := p.details(job)
+	details["data_watermark_age"] = health.Metrics.DataWatermarkAge.Round(time.Second).String()
+	details["system_lag"] = health.Metrics.SystemLag.Round(time.Second).String()
+	details["consecutive_checks"] = strconv.Itoa(health.ConsecutiveChecks)
+
+	event := p.triggerEvent(job, "unhealthy", p.severity(p.UnhealthySeverity, "warning"), details)
+	event.Payload.Summary = fmt.Sprintf("Dataflow streaming job %s is unhealthy: %s", job.Name, strings.Join(healthDetails(health), ", "))
+	event.Payload.Timestamp = time.Now().UTC().Format(time.RFC3339)
+
+	return p.trigger(ctx, job, event)
+}
+
+func (p PagerDutyHandler) HandleStuck(ctx context.Context, job model.Job, limit time.Duration) error {
+	details := p.details(job)
+	details["max_state_duration"] = limit.String()
+
+	event := p.triggerEvent(job, "stuck", p.severity(p.StuckSeverity, "warning"), details)
+	event.Payload.Summary = fmt.Sprintf("Dataflow job %s is stuck in %s since %s", job.Name, job.Status.Status, job.Status.UpdatedAt.Format(time.RFC3339))
+	event.Payload.Timestamp = time.Now().UTC().Format(time.RFC3339)
+
+	return p.trigger(ctx, job, event)
+}
+
+// HandleResolve resolves the incident of the job, if one was triggered.
+func (p PagerDutyHandler) HandleResolve(ctx context.Context, job model.Job) error {
+	if p.Store != nil {
+		_, found, err := p.Store.GetValue(ctx, p.incidentKey(job))
+		if err != nil {
+			return fmt.Errorf("failed to get incident of job %s: %w", job.Id, err)
+		}
+
+		if !found {
+			return nil
+		}
+	}
+
+	event := pagerDutyEvent{
+		RoutingKey:  p.RoutingKey,
+		EventAction: "resolve",
+		DedupKey:    PagerDutyDedupKey(job),
+	}
+
+	err := p.send(ctx, event)
+	if err != nil || p.Store == nil {
+		return err
+	}
+
+	err = p.Store.DeleteValue(ctx, p.incidentKey(job))
+	if err != nil {
+		return fmt.Errorf("failed to forget incident of job %s: %w", job.Id, err)
+	}
+
+	return nil
+}
+
+// trigger sends the event and remembers the incident of the job for its resolve.
+func (p PagerDutyHandler) trigger(ctx context.Context, job model.Job, event pagerDutyEvent) error {
+	err := p.send(ctx, event)
+	if err != nil || p.Store == nil {
+		return err
+	}
+
+	err = p.Store.SetValue(ctx, p.incidentKey(job), event.DedupKey, pagerDutyIncidentRetention)
+	if err != nil {
+		return fmt.Errorf("failed to remember incident of job %s: %w", job.Id, err)
+	}
+
+	return nil
+}
+
+// incidentKey separates the incidents of handlers with different routing keys,
+// without storing the routing key itself.
+func (p PagerDutyHandler) incidentKey(job model.Job) string {
+	hash := sha256.Sum256([]byte(p.RoutingKey))
+	return fmt.Sprintf("pagerduty:%x:%s", hash[:4], job.Id)
+}
+
+// PagerDutyDedupKey returns the dedup key that is used for all events of the given job.
+func PagerDutyDedupKey(job model.Job) string {
+	return "dmon/" + job.Id
+}
+
+func (p PagerDutyHandler) triggerEvent(job model.Job, class string, severity string, details map[string]string) pagerDutyEvent {
+	consoleUrl := p.GCPConfig.ConsoleUrl(job)
+
+	return pagerDutyEvent{
+		RoutingKey:  p.RoutingKey,
+		EventAction: "trigger",
+		DedupKey:    PagerDutyDedupKey(job),
+		Client:      "dmon",
+		ClientUrl:   consoleUrl,
+		Payload: &pagerDutyPayload{
+			Source:        fmt.Sprintf("dataflow/%s/%s", p.GCPConfig.ProjectOf(job), p.GCPConfig.LocationOf(job)),
+			Severity:      severity,
+			Component:     job.Name,
+			Group:         p.GCPConfig.ProjectOf(job),
+			Class:         class,
+			CustomDetails: details,
+		},
+		Links: []pagerDutyLink{
+			{Href: consoleUrl, Text: "Open in Dataflow UI"},
+		},
+	}
+}
+
+func (p PagerDutyHandler) details(job model.Job) map[string]string {
+	details := map[string]string{
+		"job_id":     job.Id,
+		"job_name":   job.Name,
+		"job_type":   job.Type,
+		"status":     job.Status.Status,
+		"start_time": job.StartTime.Format(time.RFC3339),
+		"runtime":    job.Runtime().Round(time.Second).String(),
+	}
+
+	if job.MaxRuntime > 0 {
+		details["max_runtime"] = job.MaxRuntime.String()
+	}
+
+	if job.SdkVersion.Version != "" {
+		details["sdk_version"] = job.SdkVersion.String()
+	}
+
+	if job.IsReplacement() {
+		details["replace_job_id"] = job.ReplaceJobId
+	}
+
+	if job.WasReplaced() {
+		details["replaced_by_job_id"] = job.ReplacedByJobId
+	}
+
+	if retry := job.Retry.String(); retry != "" {
+		details["retry"] = retry
+	}
+
+	for key, value := range job.Labels {
+		details["label_"+key] = value
+	}
+
+	return details
+}
+
+func (p PagerDutyHandler) severity(severity string, fallback string) string {
+	if severity == "" {
+		return fallback
+	}
+
+	return severity
+}
+
+func (p PagerDutyHandler) send(ctx context.Context, event pagerDutyEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode PagerDuty event: %w", err)
+	}
+
+	url := p.Url
+	if url == "" {
+		url = pagerDutyEventsUrl
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	client := http.Client{Timeout: p.Timeout}
+	res, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send PagerDuty event: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("PagerDuty responded with status %s", res.Status)
+	}
+
+	return nil
+}
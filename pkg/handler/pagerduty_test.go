@@ -0,0 +1,165 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yannickalex07/dmon/pkg/handler"
+	"github.com/yannickalex07/dmon/pkg/model"
+	"github.com/yannickalex07/dmon/pkg/storage"
+)
+
+// FAKES
+
+func newPagerDutyServer(t *testing.T, statusCode int) (*httptest.Server, *[]map[string]interface{}) {
+	received := []map[string]interface{}{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&event)
+
+		received = append(received, event)
+		w.WriteHeader(statusCode)
+	}))
+
+	t.Cleanup(server.Close)
+
+	return server, &received
+}
+
+// TESTS
+
+func TestPagerDutyHandlerHandleError(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	server, received := newPagerDutyServer(t, http.StatusAccepted)
+
+	h := handler.PagerDutyHandler{
+		RoutingKey: "routing-key",
+		Url:        server.URL,
+	}
+
+	entries := []model.LogEntry{{Text: "Something went wrong"}}
+
+	// - Act
+	err := h.HandleError(ctx, newJob(), entries)
+
+	// - Assert
+	assert.Nil(t, err)
+	assert.Len(t, *received, 1)
+
+	event := (*received)[0]
+	payload := event["payload"].(map[string]interface{})
+
+	assert.Equal(t, "routing-key", event["routing_key"])
+	assert.Equal(t, "trigger", event["event_action"])
+	assert.Equal(t, "dmon/my-job-id", event["dedup_key"])
+	assert.Equal(t, "error", payload["severity"])
+	assert.Equal(t, "Something went wrong", payload["custom_details"].(map[string]interface{})["error"])
+}
+
+func TestPagerDutyHandlerHandleTimeout(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	server, received := newPagerDutyServer(t, http.StatusAccepted)
+
+	h := handler.PagerDutyHandler{
+		RoutingKey:      "routing-key",
+		TimeoutSeverity: "critical",
+		Url:             server.URL,
+	}
+
+	// - Act
+	err := h.HandleTimeout(ctx, newJob())
+
+	// - Assert
+	assert.Nil(t, err)
+	assert.Len(t, *received, 1)
+
+	event := (*received)[0]
+	payload := event["payload"].(map[string]interface{})
+
+	assert.Equal(t, "trigger", event["event_action"])
+	assert.Equal(t, "dmon/my-job-id", event["dedup_key"])
+	assert.Equal(t, "critical", payload["severity"])
+	assert.Equal(t, "timeout", payload["class"])
+}
+
+func TestPagerDutyHandlerHandleResolve(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	server, received := newPagerDutyServer(t, http.StatusAccepted)
+
+	h := handler.PagerDutyHandler{
+		RoutingKey: "routing-key",
+		Url:        server.URL,
+	}
+
+	// - Act
+	err := h.HandleResolve(ctx, newJob())
+
+	// - Assert
+	assert.Nil(t, err)
+	assert.Len(t, *received, 1)
+
+	event := (*received)[0]
+	assert.Equal(t, "resolve", event["event_action"])
+	assert.Equal(t, "dmon/my-job-id", event["dedup_key"])
+	assert.Nil(t, event["payload"])
+}
+
+// This test asserts that only jobs with a triggered incident are resolved, if a store is set.
+func TestPagerDutyHandlerResolvesOnlyTriggeredIncidents(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	server, received := newPagerDutyServer(t, http.StatusAccepted)
+
+	h := handler.PagerDutyHandler{
+		RoutingKey: "routing-key",
+		Url:        server.URL,
+		Store:      storage.NewMemoryStore(time.Hour),
+	}
+
+	triggered := newJob()
+	other := newJob()
+	other.Id = "other-job-id"
+
+	// - Act
+	triggerErr := h.HandleTimeout(ctx, triggered)
+	otherErr := h.HandleResolve(ctx, other)
+	resolveErr := h.HandleResolve(ctx, triggered)
+	secondResolveErr := h.HandleResolve(ctx, triggered)
+
+	// - Assert
+	assert.Nil(t, triggerErr)
+	assert.Nil(t, otherErr)
+	assert.Nil(t, resolveErr)
+	assert.Nil(t, secondResolveErr)
+
+	assert.Len(t, *received, 2)
+	assert.Equal(t, "trigger", (*received)[0]["event_action"])
+	assert.Equal(t, "resolve", (*received)[1]["event_action"])
+	assert.Equal(t, "dmon/my-job-id", (*received)[1]["dedup_key"])
+}
+
+func TestPagerDutyHandlerFailsWithErrorResponse(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	server, _ := newPagerDutyServer(t, http.StatusBadRequest)
+
+	h := handler.PagerDutyHandler{
+		RoutingKey: "routing-key",
+		Url:        server.URL,
+	}
+
+	// - Act
+	err := h.HandleTimeout(ctx, newJob())
+
+	// - Assert
+	assert.Error(t, err)
+}
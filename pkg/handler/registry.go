@@ -0,0 +1,232 @@
+package handler
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/yannickalex07/dmon/pkg/config"
+	"github.com/yannickalex07/dmon/pkg/storage"
+)
+
+// Env holds everything that handlers share besides their own options.
+type Env struct {
+	GCP GCPConfig
+
+	// Store keeps values between runs, i.e. the messages that were sent for a job.
+	// It is nil if the storage does not support values.
+	Store storage.ValueStorage
+}
+
+// Factory creates a handler from the options of a handler config.
+type Factory func(cfg config.HandlerConfig, env Env) (Handler, error)
+
+var factories = map[string]Factory{
+	"slack":     newSlackHandler,
+	"webhook":   newWebhookHandler,
+	"email":     newEmailHandler,
+	"pagerduty": newPagerDutyHandler,
+	"teams":     newTeamsHandler,
+}
+
+// Register makes a handler type available in the handlers config. It is not safe for
+// concurrent use and has to be called before the handlers are built, i.e. from an init function.
+// Registering a type that already exists replaces the previous factory.
+func Register(handlerType string, factory Factory) {
+	factories[handlerType] = factory
+}
+
+// NamedHandler is a handler together with the name and the states it is configured with.
+type NamedHandler struct {
+	Name    string
+	Handler Handler
+
+	// Type is the configured type of the handler, e.g. "slack".
+	Type string
+
+	// States lists the job states that are forwarded to the handler as state changes.
+	States []string
+}
+
+// WantsState reports if a state change into the given state should be forwarded to the handler.
+func (n NamedHandler) WantsState(state string) bool {
+	for _, s := range n.States {
+		if s == state {
+			return true
+		}
+	}
+
+	return false
+}
+
+// BuildNamed creates a handler for every handler config and keeps their names.
+// Handlers without a name are named after their type, but names have to be unique.
+func BuildNamed(cfgs []config.HandlerConfig, env Env) ([]NamedHandler, error) {
+	handlers := make([]NamedHandler, 0, len(cfgs))
+	names := map[string]bool{}
+
+	for _, cfg := range cfgs {
+		if cfg.Name == "" {
+			cfg.Name = cfg.Type
+		}
+
+		if names[cfg.Name] {
+			return nil, fmt.Errorf("handler name %s is used more than once, every handler needs a unique name", cfg.Name)
+		}
+
+		names[cfg.Name] = true
+
+		for _, state := range cfg.States {
+			if !strings.HasPrefix(state, "JOB_STATE_") {
+				return nil, fmt.Errorf("invalid state %s for handler %s, states have to look like JOB_STATE_DONE", state, cfg.Name)
+			}
+		}
+
+		factory, ok := factories[cfg.Type]
+		if !ok {
+			return nil, fmt.Errorf("unknown handler type %s for handler %s", cfg.Type, cfg.Name)
+		}
+
+		h, err := factory(cfg, env)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create handler %s: %w", cfg.Name, err)
+		}
+
+		handlers = append(handlers, NamedHandler{Name: cfg.Name, Type: cfg.Type, Handler: h, States: cfg.States})
+	}
+
+	return handlers, nil
+}
+
+func newSlackHandler(cfg config.HandlerConfig, env Env) (Handler, error) {
+	var opts config.SlackConfig
+	err := cfg.Decode(&opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Token == "" || opts.Channel == "" {
+		return nil, fmt.Errorf("slack handler requires a token and a channel")
+	}
+
+	return SlackHandler{
+		Token:                 opts.Token,
+		Channel:               opts.Channel,
+		IncludeErrorSection:   opts.IncludeErrorSection,
+		IncludeDataflowButton: opts.IncludeDataflowButton,
+		IncludeActionButtons:  opts.IncludeActionButtons,
+		UpdateHeader:          opts.UpdateHeader,
+		GCPConfig:             env.GCP,
+		Store:                 env.Store,
+	}, nil
+}
+
+func newWebhookHandler(cfg config.HandlerConfig, env Env) (Handler, error) {
+	var opts config.WebhookConfig
+	err := cfg.Decode(&opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Url == "" {
+		return nil, fmt.Errorf("webhook handler requires a url")
+	}
+
+	return WebhookHandler{
+		Url:        opts.Url,
+		Headers:    opts.Headers,
+		Secret:     opts.Secret,
+		Timeout:    opts.RequestTimeout(),
+		MaxRetries: opts.MaxRetries,
+		Backoff:    1 * time.Second,
+		GCPConfig:  env.GCP,
+	}, nil
+}
+
+func newEmailHandler(cfg config.HandlerConfig, env Env) (Handler, error) {
+	var opts config.EmailConfig
+	err := cfg.Decode(&opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Host == "" || opts.From == "" || len(opts.To) == 0 {
+		return nil, fmt.Errorf("email handler requires a host, a sender and at least one recipient")
+	}
+
+	switch opts.Security {
+	case "", EmailSecurityNone, EmailSecuritySTARTTLS, EmailSecurityTLS:
+	default:
+		return nil, fmt.Errorf("unknown email security %s", opts.Security)
+	}
+
+	return EmailHandler{
+		Host:               opts.Host,
+		Port:               opts.Port,
+		Security:           opts.Security,
+		Username:           opts.Username,
+		Password:           opts.Password,
+		From:               opts.From,
+		To:                 opts.To,
+		Cc:                 opts.Cc,
+		ErrorSubject:       opts.Subjects.Error,
+		TimeoutSubject:     opts.Subjects.Timeout,
+		StateChangeSubject: opts.Subjects.StateChange,
+		UnhealthySubject:   opts.Subjects.Unhealthy,
+		StuckSubject:       opts.Subjects.Stuck,
+		FollowUpSubject:    opts.Subjects.FollowUp,
+		RemediationSubject: opts.Subjects.Remediation,
+		GCPConfig:          env.GCP,
+	}, nil
+}
+
+func newPagerDutyHandler(cfg config.HandlerConfig, env Env) (Handler, error) {
+	var opts config.PagerDutyConfig
+	err := cfg.Decode(&opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.RoutingKey == "" {
+		return nil, fmt.Errorf("pagerduty handler requires a routing key")
+	}
+
+	severities := []string{opts.Severities.Failure, opts.Severities.Timeout, opts.Severities.Unhealthy, opts.Severities.Stuck}
+	for _, severity := range severities {
+		if severity != "" && !slices.Contains(PagerDutySeverities, severity) {
+			return nil, fmt.Errorf("invalid pagerduty severity %s, has to be one of %s", severity, strings.Join(PagerDutySeverities, ", "))
+		}
+	}
+
+	return PagerDutyHandler{
+		RoutingKey:        opts.RoutingKey,
+		FailureSeverity:   opts.Severities.Failure,
+		TimeoutSeverity:   opts.Severities.Timeout,
+		UnhealthySeverity: opts.Severities.Unhealthy,
+		StuckSeverity:     opts.Severities.Stuck,
+		Timeout:           opts.RequestTimeout(),
+		GCPConfig:         env.GCP,
+		Store:             env.Store,
+	}, nil
+}
+
+func newTeamsHandler(cfg config.HandlerConfig, env Env) (Handler, error) {
+	var opts config.TeamsConfig
+	err := cfg.Decode(&opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.WebhookUrl == "" {
+		return nil, fmt.Errorf("teams handler requires a webhook url")
+	}
+
+	return TeamsHandler{
+		WebhookUrl:            opts.WebhookUrl,
+		IncludeErrorSection:   opts.IncludeErrorSection,
+		IncludeDataflowButton: opts.IncludeDataflowButton,
+		Timeout:               opts.RequestTimeout(),
+		GCPConfig:             env.GCP,
+	}, nil
+}
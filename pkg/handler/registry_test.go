@@ -0,0 +1,136 @@
+package handler_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yannickalex07/dmon/pkg/config"
+	"github.com/yannickalex07/dmon/pkg/handler"
+)
+
+func newHandlerConfig(t *testing.T, handlerType string, name string, options interface{}) config.HandlerConfig {
+	cfg, err := config.NewHandlerConfig(handlerType, name, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return cfg
+}
+
+func TestBuildNamed(t *testing.T) {
+	// - Arrange
+	gcp := handler.GCPConfig{Id: "my-project", Location: "europe-west4"}
+	env := handler.Env{GCP: gcp}
+
+	cfgs := []config.HandlerConfig{
+		newHandlerConfig(t, "slack", "data-team", config.SlackConfig{Token: "token", Channel: "data"}),
+		newHandlerConfig(t, "slack", "platform-team", config.SlackConfig{Token: "token", Channel: "platform"}),
+		newHandlerConfig(t, "webhook", "", config.WebhookConfig{Url: "https://example.com"}),
+	}
+
+	// - Act
+	handlers, err := handler.BuildNamed(cfgs, env)
+
+	// - Assert
+	assert.Nil(t, err)
+	assert.Len(t, handlers, 3)
+
+	assert.Equal(t, "data", handlers[0].Handler.(handler.SlackHandler).Channel)
+	assert.Equal(t, "platform", handlers[1].Handler.(handler.SlackHandler).Channel)
+	assert.Equal(t, gcp, handlers[2].Handler.(handler.WebhookHandler).GCPConfig)
+}
+
+func TestBuildNamedWithUnknownType(t *testing.T) {
+	// - Arrange
+	cfgs := []config.HandlerConfig{
+		newHandlerConfig(t, "carrier-pigeon", "", struct{}{}),
+	}
+
+	// - Act
+	_, err := handler.BuildNamed(cfgs, handler.Env{})
+
+	// - Assert
+	assert.Error(t, err)
+}
+
+func TestBuildNamedWithDuplicateNames(t *testing.T) {
+	// - Arrange
+	cfgs := []config.HandlerConfig{
+		newHandlerConfig(t, "slack", "", config.SlackConfig{Token: "token", Channel: "data"}),
+		newHandlerConfig(t, "slack", "", config.SlackConfig{Token: "token", Channel: "platform"}),
+	}
+
+	// - Act
+	_, err := handler.BuildNamed(cfgs, handler.Env{})
+
+	// - Assert
+	assert.Error(t, err)
+}
+
+func TestBuildNamedWithInvalidOptions(t *testing.T) {
+	// - Arrange
+	cfgs := []config.HandlerConfig{
+		newHandlerConfig(t, "slack", "", config.SlackConfig{Token: "token"}), // -> channel is missing
+	}
+
+	// - Act
+	_, err := handler.BuildNamed(cfgs, handler.Env{})
+
+	// - Assert
+	assert.Error(t, err)
+}
+
+func TestBuildNamedWithInvalidPagerDutySeverity(t *testing.T) {
+	// - Arrange
+	opts := config.PagerDutyConfig{RoutingKey: "routing-key"}
+	opts.Severities.Timeout = "warn" // -> should be warning
+
+	cfgs := []config.HandlerConfig{
+		newHandlerConfig(t, "pagerduty", "", opts),
+	}
+
+	// - Act
+	_, err := handler.BuildNamed(cfgs, handler.Env{})
+
+	// - Assert
+	assert.ErrorContains(t, err, "invalid pagerduty severity warn")
+}
+
+func TestRegister(t *testing.T) {
+	// - Arrange
+	handler.Register("custom", func(cfg config.HandlerConfig, env handler.Env) (handler.Handler, error) {
+		return handler.WebhookHandler{Url: "custom"}, nil
+	})
+
+	cfgs := []config.HandlerConfig{
+		newHandlerConfig(t, "custom", "", struct{}{}),
+	}
+
+	// - Act
+	handlers, err := handler.BuildNamed(cfgs, handler.Env{})
+
+	// - Assert
+	assert.Nil(t, err)
+	assert.Equal(t, "custom", handlers[0].Handler.(handler.WebhookHandler).Url)
+}
+
+func TestBuildNamedWithStates(t *testing.T) {
+	// - Arrange
+	cfg := newHandlerConfig(t, "slack", "successes", config.SlackConfig{Token: "token", Channel: "data"})
+	cfg.States = []string{"JOB_STATE_DONE"}
+
+	invalid := newHandlerConfig(t, "slack", "invalid", config.SlackConfig{Token: "token", Channel: "data"})
+	invalid.States = []string{"done"}
+
+	// - Act
+	handlers, err := handler.BuildNamed([]config.HandlerConfig{cfg}, handler.Env{})
+	_, invalidErr := handler.BuildNamed([]config.HandlerConfig{invalid}, handler.Env{})
+
+	// - Assert
+	assert.Nil(t, err)
+	assert.Error(t, invalidErr)
+
+	assert.Equal(t, "successes", handlers[0].Name)
+	assert.True(t, handlers[0].WantsState("JOB_STATE_DONE"))
+	assert.False(t, handlers[0].WantsState("JOB_STATE_CANCELLED"))
+}
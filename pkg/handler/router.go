@@ -0,0 +1,401 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"time"
+
+	"github.com/yannickalex07/dmon/pkg/config"
+	"github.com/yannickalex07/dmon/pkg/history"
+	"github.com/yannickalex07/dmon/pkg/metrics"
+	"github.com/yannickalex07/dmon/pkg/model"
+)
+
+// Matcher decides if an event of a job matches a route.
+// Empty fields match everything.
+type Matcher struct {
+	Name     *regexp.Regexp
+	NameGlob string
+	Labels   map[string]string
+	Events   []Event
+
+	// JobType is either "batch" or "streaming".
+	JobType string
+}
+
+func (m Matcher) Matches(event Event, job model.Job) bool {
+	if m.Name != nil && !m.Name.MatchString(job.Name) {
+		return false
+	}
+
+	if m.NameGlob != "" {
+		matches, err := path.Match(m.NameGlob, job.Name)
+		if err != nil || !matches {
+			return false
+		}
+	}
+
+	for key, value := range m.Labels {
+		if job.Labels[key] != value {
+			return false
+		}
+	}
+
+	if len(m.Events) > 0 {
+		found := false
+		for _, e := range m.Events {
+			if e == event {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	switch m.JobType {
+	case "batch":
+		return !job.IsStreaming()
+	case "streaming":
+		return job.IsStreaming()
+	}
+
+	return true
+}
+
+type Route struct {
+	Matcher  Matcher
+	Handlers []NamedHandler
+
+	// Continue controls if the following routes are evaluated after this route matched.
+	Continue bool
+}
+
+// Router is a handler that forwards every event to the handlers of the routes
+// that match the event. Routes are evaluated in order and the first matching route
+// wins, unless it is marked to continue. Events that match no route are forwarded
+// to the default handlers.
+type Router struct {
+	Routes  []Route
+	Default []NamedHandler
+
+	// History records every event with its deliveries to the handlers, it is optional.
+	History history.Store
+}
+
+// NewRouter creates a router from the routing config. The handlers of the config
+// are looked up by their name. If no default handlers are configured, events
+// that match no route are forwarded to all handlers, so that no event gets lost.
+func NewRouter(cfg config.RoutingConfig, handlers []NamedHandler) (*Router, error) {
+	byName := map[string]NamedHandler{}
+	for _, h := range handlers {
+		byName[h.Name] = h
+	}
+
+	lookup := func(names []string) ([]NamedHandler, error) {
+		found := make([]NamedHandler, 0, len(names))
+		for _, name := range names {
+			h, ok := byName[name]
+			if !ok {
+				return nil, fmt.Errorf("unknown handler %s", name)
+			}
+
+			found = append(found, h)
+		}
+
+		return found, nil
+	}
+
+	router := &Router{}
+
+	for i, routeCfg := range cfg.Routes {
+		routeHandlers, err := lookup(routeCfg.Handlers)
+		if err != nil {
+			return nil, fmt.Errorf("invalid route %d: %w", i+1, err)
+		}
+
+		matcher, err := newMatcher(routeCfg.Match)
+		if err != nil {
+			return nil, fmt.Errorf("invalid route %d: %w", i+1, err)
+		}
+
+		router.Routes = append(router.Routes, Route{
+			Matcher:  matcher,
+			Handlers: routeHandlers,
+			Continue: routeCfg.Continue,
+		})
+	}
+
+	if len(cfg.Default) == 0 {
+		router.Default = handlers
+		return router, nil
+	}
+
+	defaults, err := lookup(cfg.Default)
+	if err != nil {
+		return nil, fmt.Errorf("invalid default route: %w", err)
+	}
+
+	router.Default = defaults
+
+	return router, nil
+}
+
+func newMatcher(cfg config.MatchConfig) (Matcher, error) {
+	matcher := Matcher{
+		NameGlob: cfg.NameGlob,
+		Labels:   cfg.Labels,
+		JobType:  cfg.JobType,
+	}
+
+	if cfg.Name != "" {
+		re, err := regexp.Compile(cfg.Name)
+		if err != nil {
+			return Matcher{}, fmt.Errorf("invalid name pattern: %w", err)
+		}
+
+		matcher.Name = re
+	}
+
+	if cfg.NameGlob != "" {
+		_, err := path.Match(cfg.NameGlob, "")
+		if err != nil {
+			return Matcher{}, fmt.Errorf("invalid name glob: %w", err)
+		}
+	}
+
+	for _, e := range cfg.Events {
+		switch event := Event(e); event {
+		case EventFailure, EventTimeout, EventResolve, EventStateChange, EventUnhealthy, EventStuck, EventFollowUp, EventRemediation:
+			matcher.Events = append(matcher.Events, event)
+		default:
+			return Matcher{}, fmt.Errorf("unknown event %s", e)
+		}
+	}
+
+	switch cfg.JobType {
+	case "", "batch", "streaming":
+	default:
+		return Matcher{}, fmt.Errorf("unknown job type %s", cfg.JobType)
+	}
+
+	return matcher, nil
+}
+
+// Select returns the handlers that should receive the event of the job.
+// Every handler is returned at most once, even if multiple routes select it.
+func (r Router) Select(event Event, job model.Job) []NamedHandler {
+	selected := []NamedHandler{}
+	seen := map[string]bool{}
+	matched := false
+
+	add := func(handlers []NamedHandler) {
+		for _, h := range handlers {
+			if !seen[h.Name] {
+				seen[h.Name] = true
+				selected = append(selected, h)
+			}
+		}
+	}
+
+	for _, route := range r.Routes {
+		if !route.Matcher.Matches(event, job) {
+			continue
+		}
+
+		matched = true
+		add(route.Handlers)
+
+		if !route.Continue {
+			break
+		}
+	}
+
+	if !matched {
+		add(r.Default)
+	}
+
+	return selected
+}
+
+// Delivers reports if at least one of the selected handlers supports the event of the job,
+// state changes are only delivered to the handlers that are configured for the new state.
+func (r Router) Delivers(event Event, job model.Job) bool {
+	for _, h := range r.Select(event, job) {
+		if supports(h, event, job) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// supports reports if the handler can handle the event, it matches the handlers
+// that the forwarding functions skip.
+func supports(h NamedHandler, event Event, job model.Job) bool {
+	var ok bool
+	switch event {
+	case EventResolve:
+		_, ok = h.Handler.(ResolveHandler)
+	case EventStateChange:
+		_, ok = h.Handler.(StateChangeHandler)
+		ok = ok && h.WantsState(job.Status.Status)
+	case EventUnhealthy:
+		_, ok = h.Handler.(UnhealthyHandler)
+	case EventStuck:
+		_, ok = h.Handler.(StuckHandler)
+	case EventFollowUp:
+		_, ok = h.Handler.(FollowUpHandler)
+	case EventRemediation:
+		_, ok = h.Handler.(RemediationHandler)
+	default:
+		// failures and timeouts are supported by every handler
+		ok = true
+	}
+
+	return ok
+}
+
+func (r Router) HandleError(ctx context.Context, job model.Job, entries []model.LogEntry) error {
+	return r.forward(ctx, EventFailure, job, func(h NamedHandler) error {
+		return h.Handler.HandleError(ctx, job, entries)
+	})
+}
+
+func (r Router) HandleTimeout(ctx context.Context, job model.Job) error {
+	return r.forward(ctx, EventTimeout, job, func(h NamedHandler) error {
+		return h.Handler.HandleTimeout(ctx, job)
+	})
+}
+
+func (r Router) HandleResolve(ctx context.Context, job model.Job) error {
+	return r.forward(ctx, EventResolve, job, func(h NamedHandler) error {
+		resolver, ok := h.Handler.(ResolveHandler)
+		if !ok {
+			return errSkipped
+		}
+
+		return resolver.HandleResolve(ctx, job)
+	})
+}
+
+// HandleStateChange only forwards the state change to the selected handlers that
+// are configured for the new state of the job.
+func (r Router) HandleStateChange(ctx context.Context, job model.Job) error {
+	return r.forward(ctx, EventStateChange, job, func(h NamedHandler) error {
+		notifier, ok := h.Handler.(StateChangeHandler)
+		if !ok || !h.WantsState(job.Status.Status) {
+			return errSkipped
+		}
+
+		return notifier.HandleStateChange(ctx, job)
+	})
+}
+
+func (r Router) HandleUnhealthy(ctx context.Context, job model.Job, health model.StreamingHealth) error {
+	return r.forward(ctx, EventUnhealthy, job, func(h NamedHandler) error {
+		notifier, ok := h.Handler.(UnhealthyHandler)
+		if !ok {
+			return errSkipped
+		}
+
+		return notifier.HandleUnhealthy(ctx, job, health)
+	})
+}
+
+func (r Router) HandleStuck(ctx context.Context, job model.Job, limit time.Duration) error {
+	return r.forward(ctx, EventStuck, job, func(h NamedHandler) error {
+		notifier, ok := h.Handler.(StuckHandler)
+		if !ok {
+			return errSkipped
+		}
+
+		return notifier.HandleStuck(ctx, job, limit)
+	})
+}
+
+func (r Router) HandleFollowUp(ctx context.Context, job model.Job) error {
+	return r.forward(ctx, EventFollowUp, job, func(h NamedHandler) error {
+		notifier, ok := h.Handler.(FollowUpHandler)
+		if !ok {
+			return errSkipped
+		}
+
+		return notifier.HandleFollowUp(ctx, job)
+	})
+}
+
+func (r Router) HandleRemediation(ctx context.Context, job model.Job, remediation model.Remediation) error {
+	return r.forward(ctx, EventRemediation, job, func(h NamedHandler) error {
+		notifier, ok := h.Handler.(RemediationHandler)
+		if !ok {
+			return errSkipped
+		}
+
+		return notifier.HandleRemediation(ctx, job, remediation)
+	})
+}
+
+// errSkipped is returned by the forwarding functions for handlers that do not
+// support an event, so that these are not counted as notified.
+var errSkipped = errors.New("event not supported by handler")
+
+// forward calls the function for every selected handler and collects their errors,
+// so that a failing handler does not keep the remaining handlers from being notified.
+func (r Router) forward(ctx context.Context, event Event, job model.Job, f func(h NamedHandler) error) error {
+	var errs []error
+	notified := false
+
+	record := history.Event{
+		Time:       time.Now().UTC(),
+		Event:      string(event),
+		JobId:      job.Id,
+		JobName:    job.Name,
+		Project:    job.Project,
+		Location:   job.Location,
+		State:      job.Status.Status,
+		Deliveries: []history.Delivery{},
+	}
+
+	for _, h := range r.Select(event, job) {
+		err := f(h)
+		if errors.Is(err, errSkipped) {
+			continue
+		}
+
+		metrics.RecordHandlerCall(h.Type, string(event), err)
+
+		delivery := history.Delivery{Handler: h.Name, Type: h.Type, Success: err == nil}
+		if err != nil {
+			delivery.Error = err.Error()
+		}
+
+		record.Deliveries = append(record.Deliveries, delivery)
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("handler %s: %w", h.Name, err))
+			continue
+		}
+
+		notified = true
+	}
+
+	if notified {
+		metrics.RecordNotification(string(event))
+	}
+
+	// events that no handler ran for, i.e. because none supports them, are not recorded
+	if r.History != nil && len(record.Deliveries) > 0 {
+		err := r.History.Add(ctx, record)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to record history: %w", err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,348 @@
+package handler_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yannickalex07/dmon/pkg/config"
+	"github.com/yannickalex07/dmon/pkg/handler"
+	"github.com/yannickalex07/dmon/pkg/history"
+	"github.com/yannickalex07/dmon/pkg/metrics"
+	"github.com/yannickalex07/dmon/pkg/model"
+)
+
+// FAKES
+
+type RecordingHandler struct {
+	Errors       []model.Job
+	Timeouts     []model.Job
+	Resolves     []model.Job
+	StateChanges []model.Job
+
+	Err error
+}
+
+func (r *RecordingHandler) HandleError(ctx context.Context, job model.Job, entries []model.LogEntry) error {
+	r.Errors = append(r.Errors, job)
+	return r.Err
+}
+
+func (r *RecordingHandler) HandleTimeout(ctx context.Context, job model.Job) error {
+	r.Timeouts = append(r.Timeouts, job)
+	return r.Err
+}
+
+func (r *RecordingHandler) HandleResolve(ctx context.Context, job model.Job) error {
+	r.Resolves = append(r.Resolves, job)
+	return r.Err
+}
+
+func (r *RecordingHandler) HandleStateChange(ctx context.Context, job model.Job) error {
+	r.StateChanges = append(r.StateChanges, job)
+	return r.Err
+}
+
+func newNamedHandlers(names ...string) ([]handler.NamedHandler, map[string]*RecordingHandler) {
+	named := []handler.NamedHandler{}
+	recorders := map[string]*RecordingHandler{}
+
+	for _, name := range names {
+		recorder := &RecordingHandler{}
+		recorders[name] = recorder
+		named = append(named, handler.NamedHandler{Name: name, Handler: recorder})
+	}
+
+	return named, recorders
+}
+
+func selectedNames(selected []handler.NamedHandler) []string {
+	names := []string{}
+	for _, h := range selected {
+		names = append(names, h.Name)
+	}
+
+	return names
+}
+
+// handlerCalls returns the number of recorded calls of the handler type
+// from the metrics registry, the counter accumulates across test runs.
+func handlerCalls(t *testing.T, handlerType string) float64 {
+	families, err := metrics.Registry.Gather()
+	assert.Nil(t, err)
+
+	calls := 0.0
+	for _, family := range families {
+		if family.GetName() != "dmon_handler_calls_total" {
+			continue
+		}
+
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "type" && label.GetValue() == handlerType {
+					calls += metric.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+
+	return calls
+}
+
+// TESTS
+
+func TestRouterSelect(t *testing.T) {
+	// - Arrange
+	handlers, _ := newNamedHandlers("data-team", "platform-team", "pagerduty", "fallback")
+
+	cfg := config.RoutingConfig{
+		Routes: []config.RouteConfig{
+			{
+				Match:    config.MatchConfig{Labels: map[string]string{"env": "prod"}, Events: []string{"failure"}},
+				Handlers: []string{"pagerduty"},
+				Continue: true,
+			},
+			{
+				Match:    config.MatchConfig{Name: "^etl-"},
+				Handlers: []string{"data-team"},
+			},
+			{
+				Match:    config.MatchConfig{NameGlob: "*-export", JobType: "batch"},
+				Handlers: []string{"platform-team"},
+			},
+		},
+		Default: []string{"fallback"},
+	}
+
+	router, err := handler.NewRouter(cfg, handlers)
+	assert.Nil(t, err)
+
+	prodEtlJob := model.Job{Name: "etl-orders", Type: "JOB_TYPE_BATCH", Labels: map[string]string{"env": "prod"}}
+	etlJob := model.Job{Name: "etl-orders", Type: "JOB_TYPE_BATCH"}
+	exportJob := model.Job{Name: "orders-export", Type: "JOB_TYPE_BATCH"}
+	streamingExportJob := model.Job{Name: "orders-export", Type: "JOB_TYPE_STREAMING"}
+
+	// - Act & Assert
+	assert.Equal(t, []string{"pagerduty", "data-team"}, selectedNames(router.Select(handler.EventFailure, prodEtlJob)))
+	assert.Equal(t, []string{"data-team"}, selectedNames(router.Select(handler.EventTimeout, prodEtlJob)))
+	assert.Equal(t, []string{"data-team"}, selectedNames(router.Select(handler.EventFailure, etlJob)))
+	assert.Equal(t, []string{"platform-team"}, selectedNames(router.Select(handler.EventFailure, exportJob)))
+	assert.Equal(t, []string{"fallback"}, selectedNames(router.Select(handler.EventFailure, streamingExportJob)))
+}
+
+// This test asserts that events which match no route are sent to
+// all handlers if no default handlers are configured.
+func TestRouterSelectWithoutDefault(t *testing.T) {
+	// - Arrange
+	handlers, _ := newNamedHandlers("data-team", "platform-team")
+
+	cfg := config.RoutingConfig{
+		Routes: []config.RouteConfig{
+			{
+				Match:    config.MatchConfig{Name: "^etl-"},
+				Handlers: []string{"data-team"},
+			},
+		},
+	}
+
+	router, err := handler.NewRouter(cfg, handlers)
+	assert.Nil(t, err)
+
+	// - Act
+	selected := router.Select(handler.EventFailure, model.Job{Name: "other-job"})
+
+	// - Assert
+	assert.Equal(t, []string{"data-team", "platform-team"}, selectedNames(selected))
+}
+
+func TestRouterForwardsEvents(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	handlers, recorders := newNamedHandlers("data-team", "platform-team")
+
+	cfg := config.RoutingConfig{
+		Routes: []config.RouteConfig{
+			{
+				Match:    config.MatchConfig{Name: "^etl-"},
+				Handlers: []string{"data-team"},
+			},
+		},
+		Default: []string{"platform-team"},
+	}
+
+	router, err := handler.NewRouter(cfg, handlers)
+	assert.Nil(t, err)
+
+	etlJob := model.Job{Name: "etl-orders"}
+	otherJob := model.Job{Name: "other-job"}
+
+	// - Act
+	errorErr := router.HandleError(ctx, etlJob, nil)
+	timeoutErr := router.HandleTimeout(ctx, otherJob)
+	resolveErr := router.HandleResolve(ctx, etlJob)
+
+	// - Assert
+	assert.Nil(t, errorErr)
+	assert.Nil(t, timeoutErr)
+	assert.Nil(t, resolveErr)
+
+	assert.Equal(t, []model.Job{etlJob}, recorders["data-team"].Errors)
+	assert.Equal(t, []model.Job{etlJob}, recorders["data-team"].Resolves)
+	assert.Empty(t, recorders["data-team"].Timeouts)
+
+	assert.Equal(t, []model.Job{otherJob}, recorders["platform-team"].Timeouts)
+	assert.Empty(t, recorders["platform-team"].Errors)
+}
+
+// This test asserts that a failing handler does not keep the other handlers from being notified.
+func TestRouterForwardsEventsWithFailingHandler(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	handlers, recorders := newNamedHandlers("failing", "working")
+	recorders["failing"].Err = errors.New("error")
+
+	router, err := handler.NewRouter(config.RoutingConfig{}, handlers)
+	assert.Nil(t, err)
+
+	// - Act
+	err = router.HandleError(ctx, model.Job{Name: "job"}, nil)
+
+	// - Assert
+	assert.Error(t, err)
+	assert.Len(t, recorders["failing"].Errors, 1)
+	assert.Len(t, recorders["working"].Errors, 1)
+}
+
+func TestNewRouterWithInvalidConfig(t *testing.T) {
+	handlers, _ := newNamedHandlers("data-team")
+
+	cfgs := map[string]config.RoutingConfig{
+		"unknown handler": {Routes: []config.RouteConfig{{Handlers: []string{"unknown"}}}},
+		"unknown default": {Default: []string{"unknown"}},
+		"invalid regex":   {Routes: []config.RouteConfig{{Match: config.MatchConfig{Name: "("}, Handlers: []string{"data-team"}}}},
+		"invalid glob":    {Routes: []config.RouteConfig{{Match: config.MatchConfig{NameGlob: "["}, Handlers: []string{"data-team"}}}},
+		"unknown event":   {Routes: []config.RouteConfig{{Match: config.MatchConfig{Events: []string{"explosion"}}, Handlers: []string{"data-team"}}}},
+		"unknown type":    {Routes: []config.RouteConfig{{Match: config.MatchConfig{JobType: "hybrid"}, Handlers: []string{"data-team"}}}},
+	}
+
+	for name, cfg := range cfgs {
+		t.Run(name, func(t *testing.T) {
+			// - Act
+			_, err := handler.NewRouter(cfg, handlers)
+
+			// - Assert
+			assert.Error(t, err)
+		})
+	}
+}
+
+// This test asserts that state changes are only forwarded to
+// handlers that are configured for the new state.
+func TestRouterForwardsStateChangesByState(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	handlers, recorders := newNamedHandlers("successes", "cancellations", "nothing")
+	handlers[0].States = []string{"JOB_STATE_DONE"}
+	handlers[1].States = []string{"JOB_STATE_CANCELLED", "JOB_STATE_DRAINED"}
+
+	router, err := handler.NewRouter(config.RoutingConfig{}, handlers)
+	assert.Nil(t, err)
+
+	doneJob := model.Job{Name: "done", Status: model.Status{Status: "JOB_STATE_DONE"}}
+	cancelledJob := model.Job{Name: "cancelled", Status: model.Status{Status: "JOB_STATE_CANCELLED"}}
+
+	// - Act
+	doneErr := router.HandleStateChange(ctx, doneJob)
+	cancelledErr := router.HandleStateChange(ctx, cancelledJob)
+
+	// - Assert
+	assert.Nil(t, doneErr)
+	assert.Nil(t, cancelledErr)
+
+	assert.Equal(t, []model.Job{doneJob}, recorders["successes"].StateChanges)
+	assert.Equal(t, []model.Job{cancelledJob}, recorders["cancellations"].StateChanges)
+	assert.Empty(t, recorders["nothing"].StateChanges)
+}
+
+// This test asserts that only handlers which received an event are counted in the metrics.
+func TestRouterRecordsHandlerMetrics(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	handlers, _ := newNamedHandlers("notified", "skipped")
+	handlers[0].Type = "router-notified"
+	handlers[0].States = []string{"JOB_STATE_DONE"}
+	handlers[1].Type = "router-skipped"
+
+	router, err := handler.NewRouter(config.RoutingConfig{}, handlers)
+	assert.Nil(t, err)
+
+	notified := handlerCalls(t, "router-notified")
+
+	// - Act
+	err = router.HandleStateChange(ctx, model.Job{Name: "job", Status: model.Status{Status: "JOB_STATE_DONE"}})
+
+	// - Assert
+	assert.Nil(t, err)
+	assert.Equal(t, notified+1, handlerCalls(t, "router-notified"))
+	assert.Equal(t, 0.0, handlerCalls(t, "router-skipped"))
+}
+
+func TestRouterDelivers(t *testing.T) {
+	// - Arrange
+	handlers, _ := newNamedHandlers("done")
+	handlers[0].States = []string{"JOB_STATE_DONE"}
+
+	router, err := handler.NewRouter(config.RoutingConfig{}, handlers)
+	assert.Nil(t, err)
+
+	done := model.Job{Name: "job", Status: model.Status{Status: "JOB_STATE_DONE"}}
+	running := model.Job{Name: "job", Status: model.Status{Status: "JOB_STATE_RUNNING"}}
+
+	// - Assert
+	assert.True(t, router.Delivers(handler.EventFailure, running))
+	assert.True(t, router.Delivers(handler.EventStateChange, done))
+	assert.False(t, router.Delivers(handler.EventStateChange, running)) // -> not one of the states of the handler
+	assert.False(t, router.Delivers(handler.EventUnhealthy, running))   // -> not supported by the handler
+}
+
+// This test asserts that every event is recorded with the handlers it was delivered to
+// and that events which no handler ran for are not recorded.
+func TestRouterRecordsHistory(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	handlers, recorders := newNamedHandlers("failing", "working")
+	handlers[0].Type = "email"
+	handlers[1].Type = "slack"
+	recorders["failing"].Err = errors.New("connection refused")
+
+	router, err := handler.NewRouter(config.RoutingConfig{}, handlers)
+	assert.Nil(t, err)
+
+	store := history.NewMemoryStore()
+	router.History = store
+
+	job := model.Job{Id: "id", Name: "job", Project: "project", Status: model.Status{Status: "JOB_STATE_FAILED"}}
+
+	// - Act
+	err = router.HandleError(ctx, job, nil)
+	unhealthyErr := router.HandleUnhealthy(ctx, job, model.StreamingHealth{})
+
+	// - Assert
+	assert.Error(t, err)
+	assert.Nil(t, unhealthyErr)
+
+	events, err := store.Events(ctx, history.Query{})
+	assert.Nil(t, err)
+
+	// the unhealthy event is not recorded, as no handler supports it
+	assert.Len(t, events, 1)
+
+	assert.Equal(t, "failure", events[0].Event)
+	assert.Equal(t, "id", events[0].JobId)
+	assert.Equal(t, "JOB_STATE_FAILED", events[0].State)
+	assert.Equal(t, []history.Delivery{
+		{Handler: "failing", Type: "email", Success: false, Error: "connection refused"},
+		{Handler: "working", Type: "slack", Success: true},
+	}, events[0].Deliveries)
+}
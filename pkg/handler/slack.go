@@ -2,22 +2,17 @@ package handler
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/yannickalex07/dmon/pkg/model"
+	"github.com/yannickalex07/dmon/pkg/storage"
 
 	"github.com/slack-go/slack"
 )
 
-const dataflowUrl string = "https://console.cloud.google.com/dataflow/jobs/%s/%s?project=%s&authuser=1&hl=en"
-
-type SlackGCPConfig struct {
-	Id       string
-	Location string
-}
-
 type SlackHandler struct {
 	Token   string
 	Channel string
@@ -25,30 +20,288 @@ type SlackHandler struct {
 	IncludeErrorSection   bool
 	IncludeDataflowButton bool
 
-	GCPConfig SlackGCPConfig
+	// IncludeActionButtons adds buttons to cancel, drain, acknowledge or snooze
+	// running jobs. The buttons are handled by the interactions endpoint of dmon.
+	IncludeActionButtons bool
+
+	// UpdateHeader replaces the header of the first message of a job with the
+	// title of the latest event, i.e. from "⚠️ Job Timeout" to "❌ Job Failed".
+	UpdateHeader bool
+
+	GCPConfig GCPConfig
+
+	// Store is used to remember the first message of every job, so that later events
+	// are posted as replies in its thread. Without a store every event is sent as
+	// a new message.
+	Store storage.ValueStorage
+
+	// ApiUrl overrides the url of the Slack API, mainly used for testing.
+	ApiUrl string
+}
+
+// Ids of the interactive buttons, that are handled by the interactions endpoint.
+const (
+	SlackActionCancel      string = "dmon_cancel"
+	SlackActionDrain       string = "dmon_drain"
+	SlackActionAcknowledge string = "dmon_acknowledge"
+	SlackActionSnooze      string = "dmon_snooze"
+
+	// SlackActionsBlockId is the id of the block that contains the interactive buttons.
+	SlackActionsBlockId string = "dmon-actions"
+)
+
+// slackThreadRetention is how long the first message of a job is remembered.
+const slackThreadRetention = 7 * 24 * time.Hour
+
+// slackThread is the first message that was sent for a job.
+type slackThread struct {
+	// Channel is the id of the channel, the configured channel may be a name.
+	Channel string       `json:"channel,omitempty"`
+	Ts      string       `json:"ts"`
+	Blocks  slack.Blocks `json:"blocks"`
 }
 
 func (s SlackHandler) HandleError(ctx context.Context, job model.Job, entries []model.LogEntry) error {
 	blocks := s.createErrorBlocks(job, entries)
-	return s.send(blocks)
+	return s.notify(ctx, job, blocks)
 }
 
 func (s SlackHandler) HandleTimeout(ctx context.Context, job model.Job) error {
 	blocks := s.createTimeoutBlocks(job)
-	return s.send(blocks)
+	return s.notify(ctx, job, blocks)
+}
+
+func (s SlackHandler) HandleFollowUp(ctx context.Context, job model.Job) error {
+	blocks := s.createFollowUpBlocks(job)
+	return s.notify(ctx, job, blocks)
+}
+
+func (s SlackHandler) HandleStateChange(ctx context.Context, job model.Job) error {
+	blocks := s.createStateChangeBlocks(job)
+	return s.notify(ctx, job, blocks)
+}
+
+func (s SlackHandler) HandleUnhealthy(ctx context.Context, job model.Job, health model.StreamingHealth) error {
+	blocks := s.createUnhealthyBlocks(job, health)
+	return s.notify(ctx, job, blocks)
+}
+
+func (s SlackHandler) HandleStuck(ctx context.Context, job model.Job, limit time.Duration) error {
+	blocks := s.createStuckBlocks(job, limit)
+	return s.notify(ctx, job, blocks)
+}
+
+func (s SlackHandler) HandleRemediation(ctx context.Context, job model.Job, remediation model.Remediation) error {
+	blocks := s.createRemediationBlocks(job, remediation)
+	return s.notify(ctx, job, blocks)
+}
+
+// HandleResolve updates the header of the first message of the job, if enabled.
+// No new message is sent, as resolved jobs are reported through state changes.
+func (s SlackHandler) HandleResolve(ctx context.Context, job model.Job) error {
+	if !s.UpdateHeader || s.Store == nil {
+		return nil
+	}
+
+	thread, found, err := s.thread(ctx, job)
+	if err != nil || !found {
+		return err
+	}
+
+	return s.updateHeader(ctx, thread, stateTitle(job))
+}
+
+// notify sends the blocks as a new message if it is the first event of the job,
+// or as a reply in the thread of the first message otherwise.
+func (s SlackHandler) notify(ctx context.Context, job model.Job, blocks []slack.Block) error {
+	if s.Store == nil {
+		return s.send(blocks)
+	}
+
+	thread, found, err := s.thread(ctx, job)
+	if err != nil {
+		return err
+	}
+
+	if !found {
+		channel, ts, err := s.post(blocks)
+		if err != nil {
+			return err
+		}
+
+		return s.storeThread(ctx, job, slackThread{Channel: channel, Ts: ts, Blocks: slack.Blocks{BlockSet: blocks}})
+	}
+
+	_, _, err = s.post(blocks, slack.MsgOptionTS(thread.Ts))
+	if err != nil {
+		return err
+	}
+
+	if s.UpdateHeader {
+		if title, ok := headerOf(blocks); ok {
+			return s.updateHeader(ctx, thread, title)
+		}
+	}
+
+	return nil
 }
 
 func (s SlackHandler) send(blocks []slack.Block) error {
-	client := slack.New(s.Token)
+	_, _, err := s.post(blocks)
+	return err
+}
+
+// post sends the blocks to the channel and returns the channel id and the timestamp of the message.
+func (s SlackHandler) post(blocks []slack.Block, options ...slack.MsgOption) (string, string, error) {
+	client := s.client()
 
-	_, _, _, err := client.SendMessage(s.Channel, slack.MsgOptionBlocks(blocks...))
+	options = append([]slack.MsgOption{slack.MsgOptionBlocks(blocks...)}, options...)
+	channel, ts, _, err := client.SendMessage(s.Channel, options...)
 	if err != nil {
-		return fmt.Errorf("failed to send message with error: %w", err)
+		return "", "", fmt.Errorf("failed to send message with error: %w", err)
+	}
+
+	return channel, ts, nil
+}
+
+// updateHeader replaces the header of the first message of a thread with the title. The message is
+// rebuilt from the blocks that an interaction left behind, if there was one, so that its note is kept.
+// The buttons are removed, as they belong to the event that the message was sent for.
+func (s SlackHandler) updateHeader(ctx context.Context, thread slackThread, title string) error {
+	original, err := s.currentBlocks(ctx, thread)
+	if err != nil {
+		return err
+	}
+
+	blocks := make([]slack.Block, 0, len(original))
+	for _, block := range original {
+		if actions, ok := block.(*slack.ActionBlock); ok && actions.BlockID == SlackActionsBlockId {
+			continue
+		}
+
+		if _, ok := block.(*slack.HeaderBlock); ok {
+			block = slack.NewHeaderBlock(slack.NewTextBlockObject("plain_text", title, true, false))
+		}
+
+		blocks = append(blocks, block)
+	}
+
+	channel := thread.Channel
+	if channel == "" {
+		channel = s.Channel
+	}
+
+	client := s.client()
+
+	_, _, _, err = client.UpdateMessage(channel, thread.Ts, slack.MsgOptionBlocks(blocks...))
+	if err != nil {
+		return fmt.Errorf("failed to update message with error: %w", err)
+	}
+
+	return nil
+}
+
+// currentBlocks returns the blocks of the first message of a thread, as they were
+// left behind by an interaction or as they were sent otherwise.
+func (s SlackHandler) currentBlocks(ctx context.Context, thread slackThread) ([]slack.Block, error) {
+	if thread.Channel == "" {
+		return thread.Blocks.BlockSet, nil
+	}
+
+	value, found, err := s.Store.GetValue(ctx, slackMessageKey(thread.Channel, thread.Ts))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message %s: %w", thread.Ts, err)
+	}
+
+	if !found {
+		return thread.Blocks.BlockSet, nil
+	}
+
+	var blocks slack.Blocks
+	err = json.Unmarshal([]byte(value), &blocks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode message %s: %w", thread.Ts, err)
+	}
+
+	return blocks.BlockSet, nil
+}
+
+// StoreSlackMessage remembers the blocks of a message after an interaction replaced them,
+// so that a later update of its header does not bring back the previous blocks.
+func StoreSlackMessage(ctx context.Context, store storage.ValueStorage, channel string, ts string, blocks []slack.Block) error {
+	value, err := json.Marshal(slack.Blocks{BlockSet: blocks})
+	if err != nil {
+		return fmt.Errorf("failed to encode message %s: %w", ts, err)
+	}
+
+	err = store.SetValue(ctx, slackMessageKey(channel, ts), string(value), slackThreadRetention)
+	if err != nil {
+		return fmt.Errorf("failed to store message %s: %w", ts, err)
+	}
+
+	return nil
+}
+
+func (s SlackHandler) thread(ctx context.Context, job model.Job) (slackThread, bool, error) {
+	value, found, err := s.Store.GetValue(ctx, s.threadKey(job))
+	if err != nil {
+		return slackThread{}, false, fmt.Errorf("failed to get thread of job %s: %w", job.Id, err)
+	}
+
+	if !found {
+		return slackThread{}, false, nil
+	}
+
+	var thread slackThread
+	err = json.Unmarshal([]byte(value), &thread)
+	if err != nil {
+		return slackThread{}, false, fmt.Errorf("failed to decode thread of job %s: %w", job.Id, err)
+	}
+
+	return thread, true, nil
+}
+
+func (s SlackHandler) storeThread(ctx context.Context, job model.Job, thread slackThread) error {
+	value, err := json.Marshal(thread)
+	if err != nil {
+		return fmt.Errorf("failed to encode thread of job %s: %w", job.Id, err)
+	}
+
+	err = s.Store.SetValue(ctx, s.threadKey(job), string(value), slackThreadRetention)
+	if err != nil {
+		return fmt.Errorf("failed to store thread of job %s: %w", job.Id, err)
 	}
 
 	return nil
 }
 
+func (s SlackHandler) client() *slack.Client {
+	if s.ApiUrl != "" {
+		return slack.New(s.Token, slack.OptionAPIURL(s.ApiUrl))
+	}
+
+	return slack.New(s.Token)
+}
+
+func (s SlackHandler) threadKey(job model.Job) string {
+	return fmt.Sprintf("slack:%s:%s", s.Channel, job.Id)
+}
+
+func slackMessageKey(channel string, ts string) string {
+	return fmt.Sprintf("slack-message:%s:%s", channel, ts)
+}
+
+// headerOf returns the text of the first header block.
+func headerOf(blocks []slack.Block) (string, bool) {
+	for _, block := range blocks {
+		if header, ok := block.(*slack.HeaderBlock); ok && header.Text != nil {
+			return header.Text.Text, true
+		}
+	}
+
+	return "", false
+}
+
 func (s SlackHandler) createErrorBlocks(job model.Job, entries []model.LogEntry) []slack.Block {
 	blocks := make([]slack.Block, 0)
 
@@ -65,11 +318,8 @@ func (s SlackHandler) createErrorBlocks(job model.Job, entries []model.LogEntry)
 
 	// Error Section
 	if s.IncludeErrorSection {
-		if len(entries) > 0 {
+		if msg, ok := lastErrorLine(entries); ok {
 			// Error Text
-			cleaned := strings.TrimSpace(entries[0].Text)
-			msgParts := strings.Split(cleaned, "\n")
-			msg := msgParts[len(msgParts)-1] // last line is a blank line - before that comes the last error message
 			errorText := fmt.Sprintf("Error Message: ```%s```", msg)
 
 			errorTextBlock := slack.NewTextBlockObject("mrkdwn", errorText, false, false)
@@ -83,13 +333,14 @@ func (s SlackHandler) createErrorBlocks(job model.Job, entries []model.LogEntry)
 		}
 	}
 
+	// Details Section
+	if details := s.createDetailsBlock(job); details != nil {
+		blocks = append(blocks, details)
+	}
+
 	// Dataflow Button
 	if s.IncludeDataflowButton {
-		gcpTextBlock := slack.NewTextBlockObject("plain_text", "Open in Dataflow UI", false, false)
-		gcpButtonBlock := slack.NewButtonBlockElement("dataflow_ui", "", gcpTextBlock)
-		gcpButtonBlock.URL = fmt.Sprintf(dataflowUrl, s.GCPConfig.Location, job.Id, s.GCPConfig.Id)
-		gcpButtonActionBlock := slack.NewActionBlock("dataflow-button", gcpButtonBlock)
-		blocks = append(blocks, gcpButtonActionBlock)
+		blocks = append(blocks, s.createDataflowButtonBlock(job))
 	}
 
 	return blocks
@@ -104,18 +355,248 @@ func (s SlackHandler) createTimeoutBlocks(job model.Job) []slack.Block {
 	blocks = append(blocks, titleHeaderBlock)
 
 	// Info Section
-	infoText := fmt.Sprintf("The job `%s` with id `%s` crossed the maximum timeout limit with a runtime of *%s*.", job.Name, job.Id, job.Runtime().Round(time.Second))
+	limit := ""
+	if job.MaxRuntime > 0 {
+		limit = fmt.Sprintf(" of *%s*", job.MaxRuntime)
+	}
+
+	infoText := fmt.Sprintf("The job `%s` with id `%s` crossed the maximum timeout limit%s with a runtime of *%s*.", job.Name, job.Id, limit, job.Runtime().Round(time.Second))
+	infoTextBlock := slack.NewTextBlockObject("mrkdwn", infoText, false, false)
+	infoSectionBlock := slack.NewSectionBlock(infoTextBlock, nil, nil)
+	blocks = append(blocks, infoSectionBlock)
+
+	// Details Section
+	if details := s.createDetailsBlock(job); details != nil {
+		blocks = append(blocks, details)
+	}
+
+	// Dataflow Button
+	if s.IncludeDataflowButton {
+		blocks = append(blocks, s.createDataflowButtonBlock(job))
+	}
+
+	// Action Buttons
+	if s.IncludeActionButtons {
+		blocks = append(blocks, s.createActionsBlock(job))
+	}
+
+	return blocks
+}
+
+func (s SlackHandler) createStateChangeBlocks(job model.Job) []slack.Block {
+	blocks := make([]slack.Block, 0)
+
+	// Title
+	titleBlock := slack.NewTextBlockObject("plain_text", stateTitle(job), true, false)
+	titleHeaderBlock := slack.NewHeaderBlock(titleBlock)
+	blocks = append(blocks, titleHeaderBlock)
+
+	// Info Section
+	infoText := fmt.Sprintf("The job `%s` with id `%s` changed its state to *%s* at *%s*.", job.Name, job.Id, job.Status.Status, job.Status.UpdatedAt.Format(time.RFC1123))
 	infoTextBlock := slack.NewTextBlockObject("mrkdwn", infoText, false, false)
 	infoSectionBlock := slack.NewSectionBlock(infoTextBlock, nil, nil)
 	blocks = append(blocks, infoSectionBlock)
 
+	// Details Section
+	if details := s.createDetailsBlock(job); details != nil {
+		blocks = append(blocks, details)
+	}
+
+	// Dataflow Button
 	if s.IncludeDataflowButton {
-		gcpTextBlock := slack.NewTextBlockObject("plain_text", "Open in Dataflow UI", false, false)
-		gcpButtonBlock := slack.NewButtonBlockElement("dataflow_ui", "", gcpTextBlock)
-		gcpButtonBlock.URL = fmt.Sprintf(dataflowUrl, s.GCPConfig.Location, job.Id, s.GCPConfig.Id)
-		gcpButtonActionBlock := slack.NewActionBlock("dataflow-button", gcpButtonBlock)
-		blocks = append(blocks, gcpButtonActionBlock)
+		blocks = append(blocks, s.createDataflowButtonBlock(job))
 	}
 
 	return blocks
 }
+
+func (s SlackHandler) createUnhealthyBlocks(job model.Job, health model.StreamingHealth) []slack.Block {
+	blocks := make([]slack.Block, 0)
+
+	// Title
+	titleBlock := slack.NewTextBlockObject("plain_text", "🐢 Streaming Job Unhealthy", true, false)
+	titleHeaderBlock := slack.NewHeaderBlock(titleBlock)
+	blocks = append(blocks, titleHeaderBlock)
+
+	// Info Section
+	infoText := fmt.Sprintf("The streaming job `%s` with id `%s` was unhealthy for *%d* consecutive checks.", job.Name, job.Id, health.ConsecutiveChecks)
+	infoTextBlock := slack.NewTextBlockObject("mrkdwn", infoText, false, false)
+	infoSectionBlock := slack.NewSectionBlock(infoTextBlock, nil, nil)
+	blocks = append(blocks, infoSectionBlock)
+
+	// Metrics Section
+	metricsText := "```" + strings.Join(healthDetails(health), "\n") + "```"
+	metricsTextBlock := slack.NewTextBlockObject("mrkdwn", metricsText, false, false)
+	blocks = append(blocks, slack.NewSectionBlock(metricsTextBlock, nil, nil))
+
+	// Details Section
+	if details := s.createDetailsBlock(job); details != nil {
+		blocks = append(blocks, details)
+	}
+
+	// Dataflow Button
+	if s.IncludeDataflowButton {
+		blocks = append(blocks, s.createDataflowButtonBlock(job))
+	}
+
+	// Action Buttons
+	if s.IncludeActionButtons {
+		blocks = append(blocks, s.createActionsBlock(job))
+	}
+
+	return blocks
+}
+
+func (s SlackHandler) createStuckBlocks(job model.Job, limit time.Duration) []slack.Block {
+	blocks := make([]slack.Block, 0)
+
+	// Title
+	titleBlock := slack.NewTextBlockObject("plain_text", "⏳ Job Stuck", true, false)
+	titleHeaderBlock := slack.NewHeaderBlock(titleBlock)
+	blocks = append(blocks, titleHeaderBlock)
+
+	// Info Section
+	infoText := fmt.Sprintf("The job `%s` with id `%s` is in the state *%s* since *%s*, which is longer than the allowed *%s*.", job.Name, job.Id, job.Status.Status, job.Status.UpdatedAt.Format(time.RFC1123), limit)
+	infoTextBlock := slack.NewTextBlockObject("mrkdwn", infoText, false, false)
+	infoSectionBlock := slack.NewSectionBlock(infoTextBlock, nil, nil)
+	blocks = append(blocks, infoSectionBlock)
+
+	// Details Section
+	if details := s.createDetailsBlock(job); details != nil {
+		blocks = append(blocks, details)
+	}
+
+	// Dataflow Button
+	if s.IncludeDataflowButton {
+		blocks = append(blocks, s.createDataflowButtonBlock(job))
+	}
+
+	// Action Buttons
+	if s.IncludeActionButtons {
+		blocks = append(blocks, s.createActionsBlock(job))
+	}
+
+	return blocks
+}
+
+func (s SlackHandler) createFollowUpBlocks(job model.Job) []slack.Block {
+	blocks := make([]slack.Block, 0)
+
+	// Title
+	titleBlock := slack.NewTextBlockObject("plain_text", stateTitle(job), true, false)
+	titleHeaderBlock := slack.NewHeaderBlock(titleBlock)
+	blocks = append(blocks, titleHeaderBlock)
+
+	// Info Section
+	infoText := fmt.Sprintf("The job `%s` with id `%s` that timed out before %s.", job.Name, job.Id, followUpText(job))
+	infoTextBlock := slack.NewTextBlockObject("mrkdwn", infoText, false, false)
+	infoSectionBlock := slack.NewSectionBlock(infoTextBlock, nil, nil)
+	blocks = append(blocks, infoSectionBlock)
+
+	// Dataflow Button
+	if s.IncludeDataflowButton {
+		blocks = append(blocks, s.createDataflowButtonBlock(job))
+	}
+
+	return blocks
+}
+
+func (s SlackHandler) createRemediationBlocks(job model.Job, remediation model.Remediation) []slack.Block {
+	blocks := make([]slack.Block, 0)
+
+	// Title
+	titleBlock := slack.NewTextBlockObject("plain_text", remediationTitle(remediation), true, false)
+	titleHeaderBlock := slack.NewHeaderBlock(titleBlock)
+	blocks = append(blocks, titleHeaderBlock)
+
+	// Info Section
+	infoText := fmt.Sprintf("The job `%s` with id `%s` %s after a runtime of *%s*.", job.Name, job.Id, remediationText(remediation), job.Runtime().Round(time.Second))
+	infoTextBlock := slack.NewTextBlockObject("mrkdwn", infoText, false, false)
+	infoSectionBlock := slack.NewSectionBlock(infoTextBlock, nil, nil)
+	blocks = append(blocks, infoSectionBlock)
+
+	// Details Section
+	if details := s.createDetailsBlock(job); details != nil {
+		blocks = append(blocks, details)
+	}
+
+	// Dataflow Button
+	if s.IncludeDataflowButton {
+		blocks = append(blocks, s.createDataflowButtonBlock(job))
+	}
+
+	return blocks
+}
+
+func (s SlackHandler) createDetailsBlock(job model.Job) slack.Block {
+	details := jobDetails(job)
+	if len(details) == 0 {
+		return nil
+	}
+
+	elements := make([]slack.MixedElement, 0, len(details))
+	for _, detail := range details {
+		elements = append(elements, slack.NewTextBlockObject("mrkdwn", detail, false, false))
+	}
+
+	return slack.NewContextBlock("details", elements...)
+}
+
+func (s SlackHandler) createDataflowButtonBlock(job model.Job) slack.Block {
+	gcpTextBlock := slack.NewTextBlockObject("plain_text", "Open in Dataflow UI", false, false)
+	gcpButtonBlock := slack.NewButtonBlockElement("dataflow_ui", "", gcpTextBlock)
+	gcpButtonBlock.URL = s.GCPConfig.ConsoleUrl(job)
+
+	return slack.NewActionBlock("dataflow-button", gcpButtonBlock)
+}
+
+func (s SlackHandler) createActionsBlock(job model.Job) slack.Block {
+	value := SlackJobReference(s.GCPConfig.ProjectOf(job), s.GCPConfig.LocationOf(job), job.Id)
+
+	cancelText := slack.NewTextBlockObject("plain_text", "Cancel job", false, false)
+	cancelButton := slack.NewButtonBlockElement(SlackActionCancel, value, cancelText).WithStyle(slack.StyleDanger)
+	cancelButton.WithConfirm(s.createConfirmation("Cancel job?", fmt.Sprintf("The job `%s` will be cancelled immediately.", job.Name)))
+
+	elements := []slack.BlockElement{cancelButton}
+
+	// only streaming jobs can be drained
+	if job.IsStreaming() {
+		drainText := slack.NewTextBlockObject("plain_text", "Drain job", false, false)
+		drainButton := slack.NewButtonBlockElement(SlackActionDrain, value, drainText)
+		drainButton.WithConfirm(s.createConfirmation("Drain job?", fmt.Sprintf("The job `%s` will stop reading and finish processing the buffered data.", job.Name)))
+
+		elements = append(elements, drainButton)
+	}
+
+	acknowledgeText := slack.NewTextBlockObject("plain_text", "Acknowledge", false, false)
+	elements = append(elements, slack.NewButtonBlockElement(SlackActionAcknowledge, value, acknowledgeText))
+
+	snoozeText := slack.NewTextBlockObject("plain_text", "Snooze 1h", false, false)
+	elements = append(elements, slack.NewButtonBlockElement(SlackActionSnooze, value, snoozeText))
+
+	return slack.NewActionBlock(SlackActionsBlockId, elements...)
+}
+
+func (s SlackHandler) createConfirmation(title string, text string) *slack.ConfirmationBlockObject {
+	return slack.NewConfirmationBlockObject(
+		slack.NewTextBlockObject("plain_text", title, false, false),
+		slack.NewTextBlockObject("mrkdwn", text, false, false),
+		slack.NewTextBlockObject("plain_text", "Yes", false, false),
+		slack.NewTextBlockObject("plain_text", "No", false, false),
+	)
+}
+
+// SlackJobReference identifies the job of an interactive button.
+func SlackJobReference(project string, location string, jobId string) string {
+	return strings.Join([]string{project, location, jobId}, "/")
+}
+
+// ParseSlackJobReference returns the project, location and id of the job of an interactive button.
+func ParseSlackJobReference(value string) (model.Job, error) {
+	parts := strings.Split(value, "/")
+	if len(parts) != 3 || parts[0] == "" || parts[2] == "" {
+		return model.Job{}, fmt.Errorf("invalid job reference %s", value)
+	}
+
+	return model.Job{Project: parts[0], Location: parts[1], Id: parts[2]}, nil
+}
@@ -0,0 +1,247 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/yannickalex07/dmon/pkg/handler"
+	"github.com/yannickalex07/dmon/pkg/model"
+	"github.com/yannickalex07/dmon/pkg/storage"
+)
+
+// FAKES
+
+type SlackMessage struct {
+	Method   string
+	Channel  string
+	Ts       string
+	ThreadTs string
+	Header   string
+
+	// Actions is set if the message has interactive buttons, Note is the text of an interaction note.
+	Actions bool
+	Note    string
+}
+
+func newSlackServer(t *testing.T) (*httptest.Server, *[]SlackMessage) {
+	received := []SlackMessage{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+
+		var blocks []struct {
+			Type    string `json:"type"`
+			BlockId string `json:"block_id"`
+			Text    struct {
+				Text string `json:"text"`
+			} `json:"text"`
+			Elements []struct {
+				Text string `json:"text"`
+			} `json:"elements"`
+		}
+		json.Unmarshal([]byte(r.FormValue("blocks")), &blocks)
+
+		msg := SlackMessage{
+			Method:   strings.TrimPrefix(r.URL.Path, "/"),
+			Channel:  r.FormValue("channel"),
+			Ts:       r.FormValue("ts"),
+			ThreadTs: r.FormValue("thread_ts"),
+		}
+
+		if len(blocks) > 0 && blocks[0].Type == "header" {
+			msg.Header = blocks[0].Text.Text
+		}
+
+		for _, block := range blocks {
+			if block.Type == "actions" {
+				msg.Actions = true
+			}
+
+			if block.BlockId == "dmon-action-note" && len(block.Elements) > 0 {
+				msg.Note = block.Elements[0].Text
+			}
+		}
+
+		received = append(received, msg)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"ok": true, "channel": "C123", "ts": "1700000000.000100"}`))
+	}))
+
+	t.Cleanup(server.Close)
+
+	return server, &received
+}
+
+// TESTS
+
+func TestSlackHandlerFollowUpRepliesInThread(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	server, received := newSlackServer(t)
+
+	startTime := time.Now().UTC().Add(-2 * time.Hour)
+	job := model.Job{
+		Id:         "1",
+		Name:       "job",
+		StartTime:  startTime,
+		MaxRuntime: time.Hour,
+		Status: model.Status{
+			Status:    "JOB_STATE_RUNNING",
+			UpdatedAt: startTime,
+		},
+	}
+
+	slackHandler := handler.SlackHandler{
+		Token:   "token",
+		Channel: "alerts",
+		Store:   storage.NewMemoryStore(time.Hour),
+		ApiUrl:  server.URL + "/",
+	}
+
+	// - Act
+	timeoutErr := slackHandler.HandleTimeout(ctx, job)
+
+	job.Status = model.Status{Status: "JOB_STATE_DONE", UpdatedAt: time.Now().UTC()}
+	followUpErr := slackHandler.HandleFollowUp(ctx, job)
+
+	// - Assert
+	assert.Nil(t, timeoutErr)
+	assert.Nil(t, followUpErr)
+
+	assert.Equal(t, []SlackMessage{
+		{Method: "chat.postMessage", Channel: "alerts", Header: "⚠️ Job Timeout"},
+		{Method: "chat.postMessage", Channel: "alerts", ThreadTs: "1700000000.000100", Header: "✅ Job Succeeded"},
+	}, *received)
+}
+
+func TestSlackHandlerFollowUpWithoutStore(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	server, received := newSlackServer(t)
+
+	job := model.Job{
+		Id:         "1",
+		Name:       "job",
+		StartTime:  time.Now().UTC().Add(-2 * time.Hour),
+		MaxRuntime: time.Hour,
+		Status:     model.Status{Status: "JOB_STATE_FAILED", UpdatedAt: time.Now().UTC()},
+	}
+
+	slackHandler := handler.SlackHandler{
+		Token:   "token",
+		Channel: "alerts",
+		ApiUrl:  server.URL + "/",
+	}
+
+	// - Act
+	err := slackHandler.HandleFollowUp(ctx, job)
+
+	// - Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []SlackMessage{{Method: "chat.postMessage", Channel: "alerts", Header: "❌ Job Failed"}}, *received)
+}
+
+// This test asserts that the header of the first message is replaced
+// with the title of every later event, if enabled.
+func TestSlackHandlerUpdatesHeader(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	server, received := newSlackServer(t)
+
+	job := model.Job{
+		Id:         "1",
+		Name:       "job",
+		StartTime:  time.Now().UTC().Add(-2 * time.Hour),
+		MaxRuntime: time.Hour,
+		Status:     model.Status{Status: "JOB_STATE_RUNNING", UpdatedAt: time.Now().UTC()},
+	}
+
+	slackHandler := handler.SlackHandler{
+		Token:        "token",
+		Channel:      "alerts",
+		UpdateHeader: true,
+		Store:        storage.NewMemoryStore(time.Hour),
+		ApiUrl:       server.URL + "/",
+	}
+
+	// - Act
+	timeoutErr := slackHandler.HandleTimeout(ctx, job)
+
+	job.Status = model.Status{Status: "JOB_STATE_FAILED", UpdatedAt: time.Now().UTC()}
+	errorErr := slackHandler.HandleError(ctx, job, []model.LogEntry{})
+
+	job.Status = model.Status{Status: "JOB_STATE_DONE", UpdatedAt: time.Now().UTC()}
+	resolveErr := slackHandler.HandleResolve(ctx, job)
+
+	// - Assert
+	assert.Nil(t, timeoutErr)
+	assert.Nil(t, errorErr)
+	assert.Nil(t, resolveErr)
+
+	ts := "1700000000.000100"
+	assert.Equal(t, []SlackMessage{
+		{Method: "chat.postMessage", Channel: "alerts", Header: "⚠️ Job Timeout"},
+		{Method: "chat.postMessage", Channel: "alerts", ThreadTs: ts, Header: "❌ Job Failed"},
+		{Method: "chat.update", Channel: "C123", Ts: ts, Header: "❌ Job Failed"},
+		{Method: "chat.update", Channel: "C123", Ts: ts, Header: "✅ Job Succeeded"},
+	}, *received)
+}
+
+// This test asserts that updating the header keeps the note of an interaction
+// and does not bring back the buttons that the interaction removed.
+func TestSlackHandlerUpdatesHeaderAfterInteraction(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	server, received := newSlackServer(t)
+	store := storage.NewMemoryStore(time.Hour)
+
+	job := model.Job{
+		Id:         "1",
+		Name:       "job",
+		StartTime:  time.Now().UTC().Add(-2 * time.Hour),
+		MaxRuntime: time.Hour,
+		Status:     model.Status{Status: "JOB_STATE_RUNNING", UpdatedAt: time.Now().UTC()},
+	}
+
+	slackHandler := handler.SlackHandler{
+		Token:                "token",
+		Channel:              "alerts",
+		IncludeActionButtons: true,
+		UpdateHeader:         true,
+		Store:                store,
+		ApiUrl:               server.URL + "/",
+	}
+
+	interacted := []slack.Block{
+		slack.NewHeaderBlock(slack.NewTextBlockObject("plain_text", "⚠️ Job Timeout", true, false)),
+		slack.NewContextBlock("dmon-action-note", slack.NewTextBlockObject("mrkdwn", "🛑 Cancellation requested by <@U123>", false, false)),
+	}
+
+	// - Act
+	timeoutErr := slackHandler.HandleTimeout(ctx, job)
+	storeErr := handler.StoreSlackMessage(ctx, store, "C123", "1700000000.000100", interacted)
+
+	job.Status = model.Status{Status: "JOB_STATE_CANCELLED", UpdatedAt: time.Now().UTC()}
+	resolveErr := slackHandler.HandleResolve(ctx, job)
+
+	// - Assert
+	assert.Nil(t, timeoutErr)
+	assert.Nil(t, storeErr)
+	assert.Nil(t, resolveErr)
+
+	assert.Len(t, *received, 2)
+	assert.True(t, (*received)[0].Actions)
+
+	update := (*received)[1]
+	assert.Equal(t, "chat.update", update.Method)
+	assert.False(t, update.Actions)
+	assert.Equal(t, "🛑 Cancellation requested by <@U123>", update.Note)
+}
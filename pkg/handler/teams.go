@@ -0,0 +1,337 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/yannickalex07/dmon/pkg/model"
+)
+
+type teamsMessage struct {
+	Type        string            `json:"type"`
+	Attachments []teamsAttachment `json:"attachments"`
+}
+
+type teamsAttachment struct {
+	ContentType string            `json:"contentType"`
+	Content     teamsAdaptiveCard `json:"content"`
+}
+
+type teamsAdaptiveCard struct {
+	Schema  string             `json:"$schema"`
+	Type    string             `json:"type"`
+	Version string             `json:"version"`
+	Body    []teamsTextBlock   `json:"body"`
+	Actions []teamsOpenUrlItem `json:"actions,omitempty"`
+}
+
+type teamsTextBlock struct {
+	Type     string `json:"type"`
+	Text     string `json:"text"`
+	Size     string `json:"size,omitempty"`
+	Weight   string `json:"weight,omitempty"`
+	FontType string `json:"fontType,omitempty"`
+	Wrap     bool   `json:"wrap"`
+}
+
+type teamsOpenUrlItem struct {
+	Type  string `json:"type"`
+	Title string `json:"title"`
+	Url   string `json:"url"`
+}
+
+// TeamsHandler posts Adaptive Cards to a Microsoft Teams incoming webhook.
+type TeamsHandler struct {
+	WebhookUrl string
+
+	IncludeErrorSection   bool
+	IncludeDataflowButton bool
+
+	// Timeout limits the duration of a single request.
+	Timeout time.Duration
+
+	GCPConfig GCPConfig
+}
+
+func (t TeamsHandler) HandleError(ctx context.Context, job model.Job, entries []model.LogEntry) error {
+	card := t.createErrorCard(job, entries)
+	return t.send(ctx, card)
+}
+
+func (t TeamsHandler) HandleTimeout(ctx context.Context, job model.Job) error {
+	card := t.createTimeoutCard(job)
+	return t.send(ctx, card)
+}
+
+func (t TeamsHandler) HandleStateChange(ctx context.Context, job model.Job) error {
+	card := t.createStateChangeCard(job)
+	return t.send(ctx, card)
+}
+
+func (t TeamsHandler) HandleUnhealthy(ctx context.Context, job model.Job, health model.StreamingHealth) error {
+	card := t.createUnhealthyCard(job, health)
+	return t.send(ctx, card)
+}
+
+func (t TeamsHandler) HandleStuck(ctx context.Context, job model.Job, limit time.Duration) error {
+	card := t.createStuckCard(job, limit)
+	return t.send(ctx, card)
+}
+
+func (t TeamsHandler) HandleFollowUp(ctx context.Context, job model.Job) error {
+	card := t.createFollowUpCard(job)
+	return t.send(ctx, card)
+}
+
+func (t TeamsHandler) HandleRemediation(ctx context.Context, job model.Job, remediation model.Remediation) error {
+	card := t.createRemediationCard(job, remediation)
+	return t.send(ctx, card)
+}
+
+func (t TeamsHandler) send(ctx context.Context, card teamsAdaptiveCard) error {
+	msg := teamsMessage{
+		Type: "message",
+		Attachments: []teamsAttachment{
+			{
+				ContentType: "application/vnd.microsoft.card.adaptive",
+				Content:     card,
+			},
+		},
+	}
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode Teams message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.WebhookUrl, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	client := http.Client{Timeout: t.Timeout}
+	res, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send message with error: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("Teams responded with status %s", res.Status)
+	}
+
+	return nil
+}
+
+func (t TeamsHandler) createErrorCard(job model.Job, entries []model.LogEntry) teamsAdaptiveCard {
+	card := newTeamsCard()
+
+	// Title
+	card.Body = append(card.Body, teamsTitleBlock("❌ Job Failed"))
+
+	// Info Section
+	infoText := fmt.Sprintf("The job **%s** with id **%s** failed at **%s**!", job.Name, job.Id, job.Status.UpdatedAt.Format(time.RFC1123))
+	card.Body = append(card.Body, teamsTextBlock{Type: "TextBlock", Text: infoText, Wrap: true})
+
+	// Error Section
+	if t.IncludeErrorSection {
+		errorText := "Failed to fetch log entries."
+		if msg, ok := lastErrorLine(entries); ok {
+			errorText = msg
+		}
+
+		card.Body = append(card.Body,
+			teamsTextBlock{Type: "TextBlock", Text: "Error Message:", Weight: "Bolder", Wrap: true},
+			teamsTextBlock{Type: "TextBlock", Text: errorText, FontType: "Monospace", Wrap: true},
+		)
+	}
+
+	// Details Section
+	if details := jobDetails(job); len(details) > 0 {
+		card.Body = append(card.Body, teamsTextBlock{Type: "TextBlock", Text: strings.Join(details, " · "), Size: "Small", Wrap: true})
+	}
+
+	// Dataflow Button
+	if t.IncludeDataflowButton {
+		card.Actions = append(card.Actions, t.dataflowAction(job))
+	}
+
+	return card
+}
+
+func (t TeamsHandler) createTimeoutCard(job model.Job) teamsAdaptiveCard {
+	card := newTeamsCard()
+
+	// Title
+	card.Body = append(card.Body, teamsTitleBlock("⚠️ Job Timeout"))
+
+	// Info Section
+	limit := ""
+	if job.MaxRuntime > 0 {
+		limit = fmt.Sprintf(" of **%s**", job.MaxRuntime)
+	}
+
+	infoText := fmt.Sprintf("The job **%s** with id **%s** crossed the maximum timeout limit%s with a runtime of **%s**.", job.Name, job.Id, limit, job.Runtime().Round(time.Second))
+	card.Body = append(card.Body, teamsTextBlock{Type: "TextBlock", Text: infoText, Wrap: true})
+
+	// Details Section
+	if details := jobDetails(job); len(details) > 0 {
+		card.Body = append(card.Body, teamsTextBlock{Type: "TextBlock", Text: strings.Join(details, " · "), Size: "Small", Wrap: true})
+	}
+
+	// Dataflow Button
+	if t.IncludeDataflowButton {
+		card.Actions = append(card.Actions, t.dataflowAction(job))
+	}
+
+	return card
+}
+
+func (t TeamsHandler) createStateChangeCard(job model.Job) teamsAdaptiveCard {
+	card := newTeamsCard()
+
+	// Title
+	card.Body = append(card.Body, teamsTitleBlock(stateTitle(job)))
+
+	// Info Section
+	infoText := fmt.Sprintf("The job **%s** with id **%s** changed its state to **%s** at **%s**.", job.Name, job.Id, job.Status.Status, job.Status.UpdatedAt.Format(time.RFC1123))
+	card.Body = append(card.Body, teamsTextBlock{Type: "TextBlock", Text: infoText, Wrap: true})
+
+	// Details Section
+	if details := jobDetails(job); len(details) > 0 {
+		card.Body = append(card.Body, teamsTextBlock{Type: "TextBlock", Text: strings.Join(details, " · "), Size: "Small", Wrap: true})
+	}
+
+	// Dataflow Button
+	if t.IncludeDataflowButton {
+		card.Actions = append(card.Actions, t.dataflowAction(job))
+	}
+
+	return card
+}
+
+func (t TeamsHandler) createUnhealthyCard(job model.Job, health model.StreamingHealth) teamsAdaptiveCard {
+	card := newTeamsCard()
+
+	// Title
+	card.Body = append(card.Body, teamsTitleBlock("🐢 Streaming Job Unhealthy"))
+
+	// Info Section
+	infoText := fmt.Sprintf("The streaming job **%s** with id **%s** was unhealthy for **%d** consecutive checks.", job.Name, job.Id, health.ConsecutiveChecks)
+	card.Body = append(card.Body, teamsTextBlock{Type: "TextBlock", Text: infoText, Wrap: true})
+
+	// Metrics Section
+	for _, metric := range healthDetails(health) {
+		card.Body = append(card.Body, teamsTextBlock{Type: "TextBlock", Text: metric, FontType: "Monospace", Wrap: true})
+	}
+
+	// Details Section
+	if details := jobDetails(job); len(details) > 0 {
+		card.Body = append(card.Body, teamsTextBlock{Type: "TextBlock", Text: strings.Join(details, " · "), Size: "Small", Wrap: true})
+	}
+
+	// Dataflow Button
+	if t.IncludeDataflowButton {
+		card.Actions = append(card.Actions, t.dataflowAction(job))
+	}
+
+	return card
+}
+
+func (t TeamsHandler) createStuckCard(job model.Job, limit time.Duration) teamsAdaptiveCard {
+	card := newTeamsCard()
+
+	// Title
+	card.Body = append(card.Body, teamsTitleBlock("⏳ Job Stuck"))
+
+	// Info Section
+	infoText := fmt.Sprintf("The job **%s** with id **%s** is in the state **%s** since **%s**, which is longer than the allowed **%s**.", job.Name, job.Id, job.Status.Status, job.Status.UpdatedAt.Format(time.RFC1123), limit)
+	card.Body = append(card.Body, teamsTextBlock{Type: "TextBlock", Text: infoText, Wrap: true})
+
+	// Details Section
+	if details := jobDetails(job); len(details) > 0 {
+		card.Body = append(card.Body, teamsTextBlock{Type: "TextBlock", Text: strings.Join(details, " · "), Size: "Small", Wrap: true})
+	}
+
+	// Dataflow Button
+	if t.IncludeDataflowButton {
+		card.Actions = append(card.Actions, t.dataflowAction(job))
+	}
+
+	return card
+}
+
+func (t TeamsHandler) createFollowUpCard(job model.Job) teamsAdaptiveCard {
+	card := newTeamsCard()
+
+	// Title
+	card.Body = append(card.Body, teamsTitleBlock(stateTitle(job)))
+
+	// Info Section
+	infoText := fmt.Sprintf("The job **%s** with id **%s** that timed out before %s.", job.Name, job.Id, followUpText(job))
+	card.Body = append(card.Body, teamsTextBlock{Type: "TextBlock", Text: infoText, Wrap: true})
+
+	// Dataflow Button
+	if t.IncludeDataflowButton {
+		card.Actions = append(card.Actions, t.dataflowAction(job))
+	}
+
+	return card
+}
+
+func (t TeamsHandler) createRemediationCard(job model.Job, remediation model.Remediation) teamsAdaptiveCard {
+	card := newTeamsCard()
+
+	// Title
+	card.Body = append(card.Body, teamsTitleBlock(remediationTitle(remediation)))
+
+	// Info Section
+	infoText := fmt.Sprintf("The job **%s** with id **%s** %s after a runtime of **%s**.", job.Name, job.Id, remediationText(remediation), job.Runtime().Round(time.Second))
+	card.Body = append(card.Body, teamsTextBlock{Type: "TextBlock", Text: infoText, Wrap: true})
+
+	// Details Section
+	if details := jobDetails(job); len(details) > 0 {
+		card.Body = append(card.Body, teamsTextBlock{Type: "TextBlock", Text: strings.Join(details, " · "), Size: "Small", Wrap: true})
+	}
+
+	// Dataflow Button
+	if t.IncludeDataflowButton {
+		card.Actions = append(card.Actions, t.dataflowAction(job))
+	}
+
+	return card
+}
+
+func (t TeamsHandler) dataflowAction(job model.Job) teamsOpenUrlItem {
+	return teamsOpenUrlItem{
+		Type:  "Action.OpenUrl",
+		Title: "Open in Dataflow UI",
+		Url:   t.GCPConfig.ConsoleUrl(job),
+	}
+}
+
+func newTeamsCard() teamsAdaptiveCard {
+	return teamsAdaptiveCard{
+		Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+		Type:    "AdaptiveCard",
+		Version: "1.4",
+		Body:    []teamsTextBlock{},
+	}
+}
+
+func teamsTitleBlock(title string) teamsTextBlock {
+	return teamsTextBlock{
+		Type:   "TextBlock",
+		Text:   title,
+		Size:   "Large",
+		Weight: "Bolder",
+		Wrap:   true,
+	}
+}
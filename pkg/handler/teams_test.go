@@ -0,0 +1,133 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yannickalex07/dmon/pkg/handler"
+	"github.com/yannickalex07/dmon/pkg/model"
+)
+
+// FAKES
+
+type TeamsCard struct {
+	Type string `json:"type"`
+	Body []struct {
+		Text     string `json:"text"`
+		FontType string `json:"fontType"`
+	} `json:"body"`
+	Actions []struct {
+		Type  string `json:"type"`
+		Title string `json:"title"`
+		Url   string `json:"url"`
+	} `json:"actions"`
+}
+
+func newTeamsServer(t *testing.T) (*httptest.Server, *[]TeamsCard) {
+	received := []TeamsCard{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg struct {
+			Attachments []struct {
+				ContentType string    `json:"contentType"`
+				Content     TeamsCard `json:"content"`
+			} `json:"attachments"`
+		}
+		json.NewDecoder(r.Body).Decode(&msg)
+
+		for _, attachment := range msg.Attachments {
+			received = append(received, attachment.Content)
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	t.Cleanup(server.Close)
+
+	return server, &received
+}
+
+// TESTS
+
+func TestTeamsHandlerHandleError(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	server, received := newTeamsServer(t)
+
+	h := handler.TeamsHandler{
+		WebhookUrl:            server.URL,
+		IncludeErrorSection:   true,
+		IncludeDataflowButton: true,
+		GCPConfig: handler.GCPConfig{
+			Id:       "my-project",
+			Location: "europe-west4",
+		},
+	}
+
+	entries := []model.LogEntry{{Text: "Traceback:\nValueError: invalid input"}}
+
+	// - Act
+	err := h.HandleError(ctx, newJob(), entries)
+
+	// - Assert
+	assert.Nil(t, err)
+	assert.Len(t, *received, 1)
+
+	card := (*received)[0]
+	assert.Equal(t, "AdaptiveCard", card.Type)
+	assert.Equal(t, "❌ Job Failed", card.Body[0].Text)
+	assert.Equal(t, "ValueError: invalid input", card.Body[len(card.Body)-1].Text)
+	assert.Equal(t, "Monospace", card.Body[len(card.Body)-1].FontType)
+	assert.Len(t, card.Actions, 1)
+	assert.Equal(t, "Action.OpenUrl", card.Actions[0].Type)
+	assert.Contains(t, card.Actions[0].Url, "my-job-id")
+}
+
+func TestTeamsHandlerHandleErrorWithoutSections(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	server, received := newTeamsServer(t)
+
+	h := handler.TeamsHandler{
+		WebhookUrl:            server.URL,
+		IncludeErrorSection:   false,
+		IncludeDataflowButton: false,
+	}
+
+	// - Act
+	err := h.HandleError(ctx, newJob(), []model.LogEntry{{Text: "Error"}})
+
+	// - Assert
+	assert.Nil(t, err)
+	assert.Len(t, *received, 1)
+
+	card := (*received)[0]
+	assert.Len(t, card.Body, 2) // -> only title and info
+	assert.Empty(t, card.Actions)
+}
+
+func TestTeamsHandlerHandleTimeout(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	server, received := newTeamsServer(t)
+
+	h := handler.TeamsHandler{
+		WebhookUrl:            server.URL,
+		IncludeDataflowButton: true,
+	}
+
+	// - Act
+	err := h.HandleTimeout(ctx, newJob())
+
+	// - Assert
+	assert.Nil(t, err)
+	assert.Len(t, *received, 1)
+
+	card := (*received)[0]
+	assert.Equal(t, "⚠️ Job Timeout", card.Body[0].Text)
+	assert.Len(t, card.Actions, 1)
+}
@@ -0,0 +1,289 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/yannickalex07/dmon/pkg/model"
+)
+
+// WebhookPayloadVersion is the version of the payload that is sent by the WebhookHandler.
+// It is increased whenever a breaking change is made to the payload.
+const WebhookPayloadVersion string = "1"
+
+// WebhookSignatureHeader contains the hex encoded HMAC-SHA256 of the body,
+// if the WebhookHandler is configured with a secret.
+const WebhookSignatureHeader string = "X-Dmon-Signature"
+
+const (
+	WebhookEventFailure     string = string(EventFailure)
+	WebhookEventTimeout     string = string(EventTimeout)
+	WebhookEventStateChange string = string(EventStateChange)
+	WebhookEventUnhealthy   string = string(EventUnhealthy)
+	WebhookEventStuck       string = string(EventStuck)
+	WebhookEventFollowUp    string = string(EventFollowUp)
+	WebhookEventRemediation string = string(EventRemediation)
+)
+
+type WebhookPayload struct {
+	Version string            `json:"version"`
+	Event   string            `json:"event"`
+	SentAt  time.Time         `json:"sent_at"`
+	Job     WebhookJob        `json:"job"`
+	Errors  []WebhookLogEntry `json:"errors"`
+
+	// Health is only set for unhealthy events.
+	Health *WebhookHealth `json:"health,omitempty"`
+
+	// Stuck is only set for stuck events.
+	Stuck *WebhookStuck `json:"stuck,omitempty"`
+
+	// Remediation is only set for remediation events.
+	Remediation *WebhookRemediation `json:"remediation,omitempty"`
+}
+
+type WebhookRemediation struct {
+	Action           string `json:"action"`
+	HardLimitSeconds int64  `json:"hard_limit_seconds"`
+	DryRun           bool   `json:"dry_run"`
+	Error            string `json:"error,omitempty"`
+}
+
+type WebhookStuck struct {
+	State                   string    `json:"state"`
+	Since                   time.Time `json:"since"`
+	MaxStateDurationSeconds int64     `json:"max_state_duration_seconds"`
+}
+
+type WebhookHealth struct {
+	DataWatermarkAgeSeconds    int64 `json:"data_watermark_age_seconds"`
+	SystemLagSeconds           int64 `json:"system_lag_seconds"`
+	MaxDataWatermarkAgeSeconds int64 `json:"max_data_watermark_age_seconds"`
+	MaxSystemLagSeconds        int64 `json:"max_system_lag_seconds"`
+	ConsecutiveChecks          int   `json:"consecutive_checks"`
+}
+
+type WebhookJob struct {
+	Id              string    `json:"id"`
+	Name            string    `json:"name"`
+	Type            string    `json:"type"`
+	Status          string    `json:"status"`
+	StatusUpdatedAt time.Time `json:"status_updated_at"`
+	StartTime       time.Time `json:"start_time"`
+	RuntimeSeconds  int64     `json:"runtime_seconds"`
+	Project         string    `json:"project"`
+	Location        string    `json:"location"`
+	ConsoleUrl      string    `json:"console_url"`
+
+	Labels          map[string]string `json:"labels"`
+	CreateTime      time.Time         `json:"create_time"`
+	SdkVersion      string            `json:"sdk_version"`
+	ReplaceJobId    string            `json:"replace_job_id"`
+	ReplacedByJobId string            `json:"replaced_by_job_id"`
+	Template        string            `json:"template"`
+	WorkerRegion    string            `json:"worker_region"`
+
+	// MaxRuntimeSeconds is the runtime limit of the job, zero if it is not checked for timeouts.
+	MaxRuntimeSeconds int64 `json:"max_runtime_seconds"`
+
+	// Retry describes the relaunch of a failed job, i.e. "retry 2/3 launched as job <id>".
+	Retry string `json:"retry,omitempty"`
+}
+
+type WebhookLogEntry struct {
+	Text string    `json:"text"`
+	Time time.Time `json:"time"`
+}
+
+type WebhookHandler struct {
+	Url     string
+	Headers map[string]string
+
+	// Secret is used to sign the body, no signature is sent if it is empty.
+	Secret string
+
+	// Timeout limits the duration of a single request.
+	Timeout time.Duration
+
+	// MaxRetries is the number of retries after a failed request. The wait time between
+	// retries starts with Backoff and doubles after every retry.
+	MaxRetries int
+	Backoff    time.Duration
+
+	GCPConfig GCPConfig
+}
+
+func (w WebhookHandler) HandleError(ctx context.Context, job model.Job, entries []model.LogEntry) error {
+	payload := w.createPayload(WebhookEventFailure, job, entries)
+	return w.send(ctx, payload)
+}
+
+func (w WebhookHandler) HandleTimeout(ctx context.Context, job model.Job) error {
+	payload := w.createPayload(WebhookEventTimeout, job, nil)
+	return w.send(ctx, payload)
+}
+
+func (w WebhookHandler) HandleStateChange(ctx context.Context, job model.Job) error {
+	payload := w.createPayload(WebhookEventStateChange, job, nil)
+	return w.send(ctx, payload)
+}
+
+func (w WebhookHandler) HandleUnhealthy(ctx context.Context, job model.Job, health model.StreamingHealth) error {
+	payload := w.createPayload(WebhookEventUnhealthy, job, nil)
+	payload.Health = &WebhookHealth{
+		DataWatermarkAgeSeconds:    int64(health.Metrics.DataWatermarkAge.Seconds()),
+		SystemLagSeconds:           int64(health.Metrics.SystemLag.Seconds()),
+		MaxDataWatermarkAgeSeconds: int64(health.MaxDataWatermarkAge.Seconds()),
+		MaxSystemLagSeconds:        int64(health.MaxSystemLag.Seconds()),
+		ConsecutiveChecks:          health.ConsecutiveChecks,
+	}
+
+	return w.send(ctx, payload)
+}
+
+func (w WebhookHandler) HandleStuck(ctx context.Context, job model.Job, limit time.Duration) error {
+	payload := w.createPayload(WebhookEventStuck, job, nil)
+	payload.Stuck = &WebhookStuck{
+		State:                   job.Status.Status,
+		Since:                   job.Status.UpdatedAt,
+		MaxStateDurationSeconds: int64(limit.Seconds()),
+	}
+
+	return w.send(ctx, payload)
+}
+
+func (w WebhookHandler) HandleRemediation(ctx context.Context, job model.Job, remediation model.Remediation) error {
+	payload := w.createPayload(WebhookEventRemediation, job, nil)
+	payload.Remediation = &WebhookRemediation{
+		Action:           remediation.Action,
+		HardLimitSeconds: int64(remediation.HardLimit.Seconds()),
+		DryRun:           remediation.DryRun,
+		Error:            remediation.Error,
+	}
+
+	return w.send(ctx, payload)
+}
+
+func (w WebhookHandler) HandleFollowUp(ctx context.Context, job model.Job) error {
+	payload := w.createPayload(WebhookEventFollowUp, job, nil)
+	return w.send(ctx, payload)
+}
+
+func (w WebhookHandler) createPayload(event string, job model.Job, entries []model.LogEntry) WebhookPayload {
+	errors := make([]WebhookLogEntry, 0, len(entries))
+	for _, entry := range entries {
+		errors = append(errors, WebhookLogEntry{
+			Text: entry.Text,
+			Time: entry.Time,
+		})
+	}
+
+	// always send an object, so that receivers don't have to handle null
+	labels := job.Labels
+	if labels == nil {
+		labels = map[string]string{}
+	}
+
+	return WebhookPayload{
+		Version: WebhookPayloadVersion,
+		Event:   event,
+		SentAt:  time.Now().UTC(),
+		Job: WebhookJob{
+			Id:              job.Id,
+			Name:            job.Name,
+			Type:            job.Type,
+			Status:          job.Status.Status,
+			StatusUpdatedAt: job.Status.UpdatedAt,
+			StartTime:       job.StartTime,
+			RuntimeSeconds:  int64(job.Runtime().Seconds()),
+			Project:         w.GCPConfig.ProjectOf(job),
+			Location:        w.GCPConfig.LocationOf(job),
+			ConsoleUrl:      w.GCPConfig.ConsoleUrl(job),
+			Labels:          labels,
+			CreateTime:      job.CreateTime,
+			SdkVersion:      job.SdkVersion.Version,
+			ReplaceJobId:    job.ReplaceJobId,
+			ReplacedByJobId: job.ReplacedByJobId,
+			Template:        job.Template.Name,
+			WorkerRegion:    job.Environment.WorkerRegion,
+
+			MaxRuntimeSeconds: int64(job.MaxRuntime.Seconds()),
+			Retry:             job.Retry.String(),
+		},
+		Errors: errors,
+	}
+}
+
+func (w WebhookHandler) send(ctx context.Context, payload WebhookPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	backoff := w.Backoff
+	for attempt := 0; ; attempt++ {
+		retryable, err := w.post(ctx, body)
+		if err == nil {
+			return nil
+		}
+
+		if !retryable || attempt >= w.MaxRetries {
+			return fmt.Errorf("failed to send webhook after %d attempts: %w", attempt+1, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+	}
+}
+
+// post sends the body once and reports whether a failed request can be retried.
+func (w WebhookHandler) post(ctx context.Context, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.Url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range w.Headers {
+		req.Header.Set(key, value)
+	}
+
+	if w.Secret != "" {
+		req.Header.Set(WebhookSignatureHeader, "sha256="+SignWebhookPayload(w.Secret, body))
+	}
+
+	client := http.Client{Timeout: w.Timeout}
+	res, err := client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return false, nil
+	}
+
+	// server errors and rate limits are worth another try
+	retryable := res.StatusCode >= 500 || res.StatusCode == http.StatusTooManyRequests
+	return retryable, fmt.Errorf("webhook responded with status %s", res.Status)
+}
+
+// SignWebhookPayload returns the hex encoded HMAC-SHA256 of the body using the given secret.
+func SignWebhookPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,239 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yannickalex07/dmon/pkg/handler"
+	"github.com/yannickalex07/dmon/pkg/model"
+)
+
+// FAKES
+
+type ReceivedRequest struct {
+	Header  http.Header
+	Body    []byte
+	Payload handler.WebhookPayload
+}
+
+// Starts a server that records all requests and responds with the given status codes in order.
+// Once all status codes are used, the server responds with 200.
+func newWebhookServer(t *testing.T, statusCodes ...int) (*httptest.Server, *[]ReceivedRequest) {
+	received := []ReceivedRequest{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		var payload handler.WebhookPayload
+		json.Unmarshal(body, &payload)
+
+		received = append(received, ReceivedRequest{Header: r.Header, Body: body, Payload: payload})
+
+		if len(received) <= len(statusCodes) {
+			w.WriteHeader(statusCodes[len(received)-1])
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	t.Cleanup(server.Close)
+
+	return server, &received
+}
+
+func newJob() model.Job {
+	now := time.Now().UTC()
+
+	return model.Job{
+		Id:   "my-job-id",
+		Name: "my-job",
+		Type: "JOB_TYPE_BATCH",
+		Status: model.Status{
+			Status:    "JOB_STATE_FAILED",
+			UpdatedAt: now,
+		},
+		StartTime: now.Add(-1 * time.Hour),
+	}
+}
+
+// TESTS
+
+func TestWebhookHandlerHandleError(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	server, received := newWebhookServer(t)
+
+	job := newJob()
+	entries := []model.LogEntry{
+		{Text: "Something went wrong", Time: time.Now().UTC()},
+	}
+
+	h := handler.WebhookHandler{
+		Url:     server.URL,
+		Headers: map[string]string{"Authorization": "Bearer token"},
+		Secret:  "my-secret",
+		GCPConfig: handler.GCPConfig{
+			Id:       "my-project",
+			Location: "europe-west4",
+		},
+	}
+
+	// - Act
+	err := h.HandleError(ctx, job, entries)
+
+	// - Assert
+	assert.Nil(t, err)
+	assert.Len(t, *received, 1)
+
+	req := (*received)[0]
+	assert.Equal(t, "Bearer token", req.Header.Get("Authorization"))
+	assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+	assert.Equal(t, "sha256="+handler.SignWebhookPayload("my-secret", req.Body), req.Header.Get(handler.WebhookSignatureHeader))
+
+	assert.Equal(t, handler.WebhookPayloadVersion, req.Payload.Version)
+	assert.Equal(t, handler.WebhookEventFailure, req.Payload.Event)
+	assert.Equal(t, "my-job-id", req.Payload.Job.Id)
+	assert.Equal(t, "JOB_STATE_FAILED", req.Payload.Job.Status)
+	assert.Equal(t, "my-project", req.Payload.Job.Project)
+	assert.Equal(t, "europe-west4", req.Payload.Job.Location)
+	assert.Contains(t, req.Payload.Job.ConsoleUrl, "my-job-id")
+	assert.Equal(t, "Something went wrong", req.Payload.Errors[0].Text)
+}
+
+func TestWebhookHandlerIncludesJobMetadata(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	server, received := newWebhookServer(t, http.StatusOK)
+
+	job := newJob()
+	job.Labels = map[string]string{"team": "data"}
+	job.SdkVersion = model.SdkVersion{Version: "2.48.0"}
+	job.ReplacedByJobId = "my-new-job-id"
+
+	h := handler.WebhookHandler{Url: server.URL}
+
+	// - Act
+	err := h.HandleStateChange(ctx, job)
+
+	// - Assert
+	assert.Nil(t, err)
+	assert.Len(t, *received, 1)
+
+	payload := (*received)[0].Payload
+	assert.Equal(t, handler.WebhookEventStateChange, payload.Event)
+	assert.Equal(t, map[string]string{"team": "data"}, payload.Job.Labels)
+	assert.Equal(t, "2.48.0", payload.Job.SdkVersion)
+	assert.Equal(t, "my-new-job-id", payload.Job.ReplacedByJobId)
+}
+
+func TestWebhookHandlerHandleTimeout(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	server, received := newWebhookServer(t)
+
+	h := handler.WebhookHandler{Url: server.URL}
+
+	// - Act
+	err := h.HandleTimeout(ctx, newJob())
+
+	// - Assert
+	assert.Nil(t, err)
+	assert.Len(t, *received, 1)
+
+	req := (*received)[0]
+	assert.Empty(t, req.Header.Get(handler.WebhookSignatureHeader)) // -> no secret, no signature
+	assert.Equal(t, handler.WebhookEventTimeout, req.Payload.Event)
+	assert.Equal(t, int64(3600), req.Payload.Job.RuntimeSeconds)
+	assert.Empty(t, req.Payload.Errors)
+}
+
+func TestWebhookHandlerHandleRemediation(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	server, received := newWebhookServer(t)
+
+	h := handler.WebhookHandler{Url: server.URL}
+
+	remediation := model.Remediation{
+		Action:    model.RemediationCancel,
+		HardLimit: 4 * time.Hour,
+		DryRun:    true,
+	}
+
+	// - Act
+	err := h.HandleRemediation(ctx, newJob(), remediation)
+
+	// - Assert
+	assert.Nil(t, err)
+	assert.Len(t, *received, 1)
+
+	payload := (*received)[0].Payload
+	assert.Equal(t, handler.WebhookEventRemediation, payload.Event)
+	assert.Equal(t, &handler.WebhookRemediation{Action: "cancel", HardLimitSeconds: 14400, DryRun: true}, payload.Remediation)
+}
+
+// This test asserts that a request that fails with a server error is retried.
+func TestWebhookHandlerRetriesServerErrors(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	server, received := newWebhookServer(t, http.StatusInternalServerError, http.StatusBadGateway)
+
+	h := handler.WebhookHandler{
+		Url:        server.URL,
+		MaxRetries: 2,
+		Backoff:    1 * time.Millisecond,
+	}
+
+	// - Act
+	err := h.HandleTimeout(ctx, newJob())
+
+	// - Assert
+	assert.Nil(t, err)
+	assert.Len(t, *received, 3)
+}
+
+func TestWebhookHandlerFailsAfterMaxRetries(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	server, received := newWebhookServer(t, http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+
+	h := handler.WebhookHandler{
+		Url:        server.URL,
+		MaxRetries: 1,
+		Backoff:    1 * time.Millisecond,
+	}
+
+	// - Act
+	err := h.HandleTimeout(ctx, newJob())
+
+	// - Assert
+	assert.Error(t, err)
+	assert.Len(t, *received, 2)
+}
+
+// This test asserts that client errors are not retried, as they won't succeed on another try.
+func TestWebhookHandlerDoesNotRetryClientErrors(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	server, received := newWebhookServer(t, http.StatusBadRequest)
+
+	h := handler.WebhookHandler{
+		Url:        server.URL,
+		MaxRetries: 3,
+		Backoff:    1 * time.Millisecond,
+	}
+
+	// - Act
+	err := h.HandleTimeout(ctx, newJob())
+
+	// - Assert
+	assert.Error(t, err)
+	assert.Len(t, *received, 1)
+}
@@ -0,0 +1,170 @@
+package health
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Run is a single execution of the monitor.
+type Run struct {
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Err        error
+
+	// Skipped runs were not executed because this instance is not the leader,
+	// they only check that the Dataflow API can be requested.
+	Skipped bool
+}
+
+func (r Run) Duration() time.Duration {
+	return r.FinishedAt.Sub(r.StartedAt)
+}
+
+// Tracker keeps the last runs of the monitor to report the health and readiness of dmon.
+type Tracker struct {
+	// MaxAge is how long ago the last successful run may have finished for dmon to be ready.
+	MaxAge time.Duration
+
+	mu          sync.Mutex
+	startedAt   time.Time
+	last        *Run
+	lastSuccess *Run
+}
+
+func NewTracker(maxAge time.Duration) *Tracker {
+	return &Tracker{
+		MaxAge:    maxAge,
+		startedAt: time.Now(),
+	}
+}
+
+// Record stores a finished run. Skipped runs count as successful if their check of the
+// Dataflow API succeeded, so that followers with broken credentials are not ready either.
+func (t *Tracker) Record(run Run) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.last = &run
+	if run.Err == nil {
+		t.lastSuccess = &run
+	}
+}
+
+// Ready reports if the last successful run finished within the max age,
+// otherwise the reason why dmon is not ready is returned.
+func (t *Tracker) Ready() (bool, string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.ready()
+}
+
+func (t *Tracker) ready() (bool, string) {
+	if t.lastSuccess == nil {
+		if t.last == nil {
+			return false, "no run finished yet"
+		}
+
+		return false, "no run succeeded yet"
+	}
+
+	age := time.Since(t.lastSuccess.FinishedAt)
+	if age > t.MaxAge {
+		return false, fmt.Sprintf("last successful run finished %s ago, more than %s", age.Round(time.Second), t.MaxAge)
+	}
+
+	return true, ""
+}
+
+type runResponse struct {
+	StartedAt       time.Time `json:"started_at"`
+	FinishedAt      time.Time `json:"finished_at"`
+	DurationSeconds float64   `json:"duration_seconds"`
+	Error           string    `json:"error,omitempty"`
+	Skipped         bool      `json:"skipped,omitempty"`
+}
+
+func newRunResponse(run *Run) *runResponse {
+	if run == nil {
+		return nil
+	}
+
+	res := &runResponse{
+		StartedAt:       run.StartedAt,
+		FinishedAt:      run.FinishedAt,
+		DurationSeconds: run.Duration().Seconds(),
+		Skipped:         run.Skipped,
+	}
+
+	if run.Err != nil {
+		res.Error = run.Err.Error()
+	}
+
+	return res
+}
+
+type statusResponse struct {
+	Status            string       `json:"status"`
+	Reason            string       `json:"reason,omitempty"`
+	UptimeSeconds     float64      `json:"uptime_seconds"`
+	LastRun           *runResponse `json:"last_run"`
+	LastSuccessfulRun *runResponse `json:"last_successful_run"`
+}
+
+func (t *Tracker) status(ready bool, reason string) statusResponse {
+	status := "ok"
+	if !ready {
+		status = "unavailable"
+	}
+
+	return statusResponse{
+		Status:            status,
+		Reason:            reason,
+		UptimeSeconds:     time.Since(t.startedAt).Seconds(),
+		LastRun:           newRunResponse(t.last),
+		LastSuccessfulRun: newRunResponse(t.lastSuccess),
+	}
+}
+
+// Healthz reports that the process is alive, it always responds with 200.
+func (t *Tracker) Healthz() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.mu.Lock()
+		res := t.status(true, "")
+		t.mu.Unlock()
+
+		writeJSON(w, http.StatusOK, res)
+	})
+}
+
+// Readyz responds with 200 if dmon is ready and with 503 otherwise.
+func (t *Tracker) Readyz() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.mu.Lock()
+		ready, reason := t.ready()
+		res := t.status(ready, reason)
+		t.mu.Unlock()
+
+		code := http.StatusOK
+		if !ready {
+			code = http.StatusServiceUnavailable
+		}
+
+		writeJSON(w, code, res)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, code int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	err := json.NewEncoder(w).Encode(body)
+	if err != nil {
+		log.Errorf("failed to write health response: %s", err.Error())
+	}
+}
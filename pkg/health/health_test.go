@@ -0,0 +1,134 @@
+package health_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yannickalex07/dmon/pkg/health"
+)
+
+func request(t *testing.T, h http.Handler) (int, map[string]any) {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+
+	body := map[string]any{}
+	err := json.Unmarshal(rec.Body.Bytes(), &body)
+	assert.Nil(t, err)
+
+	return rec.Code, body
+}
+
+func TestHealthzIsAlwaysOk(t *testing.T) {
+	// - Arrange
+	tracker := health.NewTracker(time.Minute)
+
+	// - Act
+	code, body := request(t, tracker.Healthz())
+
+	// - Assert
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ok", body["status"])
+}
+
+func TestReadyzWithoutRun(t *testing.T) {
+	// - Arrange
+	tracker := health.NewTracker(time.Minute)
+
+	// - Act
+	code, body := request(t, tracker.Readyz())
+
+	// - Assert
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "unavailable", body["status"])
+	assert.Equal(t, "no run finished yet", body["reason"])
+}
+
+func TestReadyzWithSuccessfulRun(t *testing.T) {
+	// - Arrange
+	tracker := health.NewTracker(time.Minute)
+	now := time.Now()
+	tracker.Record(health.Run{StartedAt: now.Add(-2 * time.Second), FinishedAt: now})
+
+	// - Act
+	code, body := request(t, tracker.Readyz())
+
+	// - Assert
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ok", body["status"])
+
+	lastRun := body["last_run"].(map[string]any)
+	assert.Equal(t, 2.0, lastRun["duration_seconds"])
+	assert.NotContains(t, lastRun, "error")
+}
+
+// This test asserts that a single failed run does not affect the readiness,
+// as long as the last successful run is recent enough.
+func TestReadyzWithFailedRunAfterSuccessfulRun(t *testing.T) {
+	// - Arrange
+	tracker := health.NewTracker(time.Minute)
+	now := time.Now()
+	tracker.Record(health.Run{StartedAt: now.Add(-30 * time.Second), FinishedAt: now.Add(-30 * time.Second)})
+	tracker.Record(health.Run{StartedAt: now, FinishedAt: now, Err: errors.New("failed to list jobs")})
+
+	// - Act
+	code, body := request(t, tracker.Readyz())
+
+	// - Assert
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "failed to list jobs", body["last_run"].(map[string]any)["error"])
+}
+
+func TestReadyzWithOutdatedSuccessfulRun(t *testing.T) {
+	// - Arrange
+	tracker := health.NewTracker(time.Minute)
+	now := time.Now()
+	tracker.Record(health.Run{StartedAt: now.Add(-5 * time.Minute), FinishedAt: now.Add(-5 * time.Minute)})
+	tracker.Record(health.Run{StartedAt: now, FinishedAt: now, Err: errors.New("failed to list jobs")})
+
+	// - Act
+	code, body := request(t, tracker.Readyz())
+
+	// - Assert
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Contains(t, body["reason"], "last successful run finished")
+}
+
+func TestReadyzWithOnlyFailedRuns(t *testing.T) {
+	// - Arrange
+	tracker := health.NewTracker(time.Minute)
+	now := time.Now()
+	tracker.Record(health.Run{StartedAt: now, FinishedAt: now, Err: errors.New("failed to list jobs")})
+
+	// - Act
+	ready, reason := tracker.Ready()
+
+	// - Assert
+	assert.False(t, ready)
+	assert.Equal(t, "no run succeeded yet", reason)
+}
+
+// This test asserts that followers are only ready if their check of the Dataflow API succeeded.
+func TestReadyzWithSkippedRuns(t *testing.T) {
+	// - Arrange
+	now := time.Now()
+
+	checked := health.NewTracker(time.Minute)
+	checked.Record(health.Run{StartedAt: now, FinishedAt: now, Skipped: true})
+
+	failed := health.NewTracker(time.Minute)
+	failed.Record(health.Run{StartedAt: now, FinishedAt: now, Skipped: true, Err: errors.New("permission denied")})
+
+	// - Act
+	checkedReady, _ := checked.Ready()
+	failedReady, failedReason := failed.Ready()
+
+	// - Assert
+	assert.True(t, checkedReady)
+	assert.False(t, failedReady)
+	assert.Equal(t, "no run succeeded yet", failedReason)
+}
@@ -0,0 +1,66 @@
+package history
+
+import (
+	"context"
+	"time"
+)
+
+// Event is a detected event of a job together with the attempts to deliver it to the handlers.
+type Event struct {
+	Id       int64     `json:"id"`
+	Time     time.Time `json:"time"`
+	Event    string    `json:"event"`
+	JobId    string    `json:"job_id"`
+	JobName  string    `json:"job_name"`
+	Project  string    `json:"project"`
+	Location string    `json:"location"`
+
+	// State is the state of the job when the event was detected.
+	State string `json:"state"`
+
+	Deliveries []Delivery `json:"deliveries"`
+}
+
+// Delivery is an attempt to deliver an event to a handler.
+type Delivery struct {
+	Handler string `json:"handler"`
+	Type    string `json:"type"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Query filters the events, empty fields match all events.
+type Query struct {
+	JobId string
+	Event string
+	Since time.Time
+
+	// Limit is the maximum number of returned events, zero returns all events.
+	Limit int
+}
+
+func (q Query) Matches(event Event) bool {
+	if q.JobId != "" && event.JobId != q.JobId {
+		return false
+	}
+
+	if q.Event != "" && event.Event != q.Event {
+		return false
+	}
+
+	return q.Since.IsZero() || !event.Time.Before(q.Since)
+}
+
+// Store persists the event history.
+type Store interface {
+	// Add stores the event with its deliveries.
+	Add(ctx context.Context, event Event) error
+
+	// Events returns the events that match the query, newest first.
+	Events(ctx context.Context, query Query) ([]Event, error)
+
+	// Prune deletes all events before the given time and returns the number of deleted events.
+	Prune(ctx context.Context, before time.Time) (int64, error)
+
+	Close() error
+}
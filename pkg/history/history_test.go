@@ -0,0 +1,183 @@
+package history_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yannickalex07/dmon/pkg/history"
+)
+
+func newStores(t *testing.T) map[string]history.Store {
+	sqlite, err := history.NewSQLiteStore(filepath.Join(t.TempDir(), "history.db"))
+	assert.Nil(t, err)
+	t.Cleanup(func() { sqlite.Close() })
+
+	return map[string]history.Store{
+		"sqlite": sqlite,
+		"memory": history.NewMemoryStore(),
+	}
+}
+
+func newEvent(event string, jobId string, at time.Time) history.Event {
+	return history.Event{
+		Time:       at,
+		Event:      event,
+		JobId:      jobId,
+		JobName:    "job",
+		Project:    "project",
+		Location:   "europe-west1",
+		State:      "JOB_STATE_FAILED",
+		Deliveries: []history.Delivery{},
+	}
+}
+
+func TestStoreAddAndQueryEvents(t *testing.T) {
+	for name, store := range newStores(t) {
+		t.Run(name, func(t *testing.T) {
+			// - Arrange
+			ctx := context.Background()
+			now := time.Now().UTC().Truncate(time.Second)
+
+			failure := newEvent("failure", "a", now.Add(-2*time.Hour))
+			failure.Deliveries = []history.Delivery{
+				{Handler: "slack", Type: "slack", Success: true},
+				{Handler: "email", Type: "email", Success: false, Error: "connection refused"},
+			}
+
+			assert.Nil(t, store.Add(ctx, failure))
+			assert.Nil(t, store.Add(ctx, newEvent("timeout", "b", now.Add(-1*time.Hour))))
+			assert.Nil(t, store.Add(ctx, newEvent("timeout", "a", now)))
+
+			// - Act
+			all, allErr := store.Events(ctx, history.Query{})
+			byJob, byJobErr := store.Events(ctx, history.Query{JobId: "a", Event: "failure"})
+			recent, recentErr := store.Events(ctx, history.Query{Since: now.Add(-90 * time.Minute)})
+			limited, limitedErr := store.Events(ctx, history.Query{Limit: 1})
+
+			// - Assert
+			assert.Nil(t, allErr)
+			assert.Nil(t, byJobErr)
+			assert.Nil(t, recentErr)
+			assert.Nil(t, limitedErr)
+
+			assert.Len(t, all, 3)
+			assert.Equal(t, now, all[0].Time)
+			assert.Empty(t, all[0].Deliveries)
+
+			assert.Len(t, byJob, 1)
+			assert.Equal(t, failure.Deliveries, byJob[0].Deliveries)
+			assert.Equal(t, "europe-west1", byJob[0].Location)
+			assert.True(t, failure.Time.Equal(byJob[0].Time))
+
+			assert.Len(t, recent, 2)
+			assert.Len(t, limited, 1)
+			assert.Equal(t, "a", limited[0].JobId)
+		})
+	}
+}
+
+func TestStorePrune(t *testing.T) {
+	for name, store := range newStores(t) {
+		t.Run(name, func(t *testing.T) {
+			// - Arrange
+			ctx := context.Background()
+			now := time.Now().UTC()
+
+			old := newEvent("failure", "old", now.Add(-48*time.Hour))
+			old.Deliveries = []history.Delivery{{Handler: "slack", Type: "slack", Success: true}}
+
+			assert.Nil(t, store.Add(ctx, old))
+			assert.Nil(t, store.Add(ctx, newEvent("failure", "new", now)))
+
+			// - Act
+			deleted, err := store.Prune(ctx, now.Add(-24*time.Hour))
+
+			// - Assert
+			assert.Nil(t, err)
+			assert.Equal(t, int64(1), deleted)
+
+			events, err := store.Events(ctx, history.Query{})
+			assert.Nil(t, err)
+			assert.Len(t, events, 1)
+			assert.Equal(t, "new", events[0].JobId)
+		})
+	}
+}
+
+// This test asserts that the history is kept when the database is opened again.
+func TestSQLiteStorePersistsEvents(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "history.db")
+
+	store, err := history.NewSQLiteStore(path)
+	assert.Nil(t, err)
+	assert.Nil(t, store.Add(ctx, newEvent("failure", "a", time.Now())))
+	assert.Nil(t, store.Close())
+
+	// - Act
+	reopened, err := history.NewSQLiteStore(path)
+	assert.Nil(t, err)
+	defer reopened.Close()
+
+	events, err := reopened.Events(ctx, history.Query{})
+
+	// - Assert
+	assert.Nil(t, err)
+	assert.Len(t, events, 1)
+}
+
+func TestParseSince(t *testing.T) {
+	// - Arrange
+	now := time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC)
+
+	// - Act
+	empty, emptyErr := history.ParseSince("", now)
+	relative, relativeErr := history.ParseSince("24h", now)
+	absolute, absoluteErr := history.ParseSince("2024-01-01T06:00:00Z", now)
+	_, invalidErr := history.ParseSince("yesterday", now)
+
+	// - Assert
+	assert.Nil(t, emptyErr)
+	assert.True(t, empty.IsZero())
+
+	assert.Nil(t, relativeErr)
+	assert.Equal(t, time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC), relative)
+
+	assert.Nil(t, absoluteErr)
+	assert.Equal(t, time.Date(2024, 1, 1, 6, 0, 0, 0, time.UTC), absolute)
+
+	assert.Error(t, invalidErr)
+}
+
+func TestHandler(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	store := history.NewMemoryStore()
+	assert.Nil(t, store.Add(ctx, newEvent("failure", "a", time.Now())))
+	assert.Nil(t, store.Add(ctx, newEvent("timeout", "b", time.Now())))
+
+	rec := httptest.NewRecorder()
+	invalid := httptest.NewRecorder()
+
+	// - Act
+	history.Handler(store).ServeHTTP(rec, httptest.NewRequest("GET", "/api/history?event=failure&since=1h", nil))
+	history.Handler(store).ServeHTTP(invalid, httptest.NewRequest("GET", "/api/history?limit=none", nil))
+
+	// - Assert
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	events := []history.Event{}
+	err := json.Unmarshal(rec.Body.Bytes(), &events)
+	assert.Nil(t, err)
+	assert.Len(t, events, 1)
+	assert.Equal(t, "a", events[0].JobId)
+
+	assert.Equal(t, http.StatusBadRequest, invalid.Code)
+}
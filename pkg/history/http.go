@@ -0,0 +1,75 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// DefaultLimit is the number of events that are returned if no limit is requested.
+const DefaultLimit = 100
+
+// ParseSince parses either a duration relative to now, i.e. "24h", or an RFC 3339 timestamp.
+func ParseSince(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err == nil {
+		return now.Add(-d), nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid since %s, expected a duration or an RFC 3339 timestamp", value)
+	}
+
+	return t, nil
+}
+
+// Handler serves the events of the store as JSON. The events can be filtered
+// by the query parameters job_id, event, since and limit.
+func Handler(store Store) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		params := r.URL.Query()
+
+		since, err := ParseSince(params.Get("since"), time.Now())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		limit := DefaultLimit
+		if value := params.Get("limit"); value != "" {
+			limit, err = strconv.Atoi(value)
+			if err != nil || limit <= 0 {
+				http.Error(w, fmt.Sprintf("invalid limit %s", value), http.StatusBadRequest)
+				return
+			}
+		}
+
+		events, err := store.Events(r.Context(), Query{
+			JobId: params.Get("job_id"),
+			Event: params.Get("event"),
+			Since: since,
+			Limit: limit,
+		})
+		if err != nil {
+			log.Errorf("failed to query history: %s", err.Error())
+			http.Error(w, "failed to query history", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		err = json.NewEncoder(w).Encode(events)
+		if err != nil {
+			log.Errorf("failed to write history response: %s", err.Error())
+		}
+	})
+}
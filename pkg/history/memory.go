@@ -0,0 +1,69 @@
+package history
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps the history in memory, it is lost on restart.
+type MemoryStore struct {
+	mu     sync.Mutex
+	events []Event
+	nextId int64
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{nextId: 1}
+}
+
+func (s *MemoryStore) Add(ctx context.Context, event Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	event.Id = s.nextId
+	s.nextId++
+
+	s.events = append(s.events, event)
+
+	return nil
+}
+
+func (s *MemoryStore) Events(ctx context.Context, query Query) ([]Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	events := []Event{}
+	for i := len(s.events) - 1; i >= 0; i-- {
+		if query.Limit > 0 && len(events) >= query.Limit {
+			break
+		}
+
+		if query.Matches(s.events[i]) {
+			events = append(events, s.events[i])
+		}
+	}
+
+	return events, nil
+}
+
+func (s *MemoryStore) Prune(ctx context.Context, before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	kept := []Event{}
+	for _, event := range s.events {
+		if !event.Time.Before(before) {
+			kept = append(kept, event)
+		}
+	}
+
+	deleted := int64(len(s.events) - len(kept))
+	s.events = kept
+
+	return deleted, nil
+}
+
+func (s *MemoryStore) Close() error {
+	return nil
+}
@@ -0,0 +1,215 @@
+package history
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS events (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	time INTEGER NOT NULL,
+	event TEXT NOT NULL,
+	job_id TEXT NOT NULL,
+	job_name TEXT NOT NULL,
+	project TEXT NOT NULL,
+	location TEXT NOT NULL,
+	state TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS events_time ON events (time);
+CREATE INDEX IF NOT EXISTS events_job_id ON events (job_id);
+
+CREATE TABLE IF NOT EXISTS deliveries (
+	event_id INTEGER NOT NULL,
+	handler TEXT NOT NULL,
+	type TEXT NOT NULL,
+	success INTEGER NOT NULL,
+	error TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS deliveries_event_id ON deliveries (event_id);
+`
+
+// SQLiteStore persists the history in a SQLite database file.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteStore opens the database at the path and creates the tables if they do not exist yet.
+func NewSQLiteStore(path string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite", fmt.Sprintf("file:%s?_pragma=busy_timeout(5000)", path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history database: %w", err)
+	}
+
+	// SQLite only allows a single writer, a single connection avoids locking errors.
+	db.SetMaxOpenConns(1)
+
+	_, err = db.Exec(schema)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create history tables: %w", err)
+	}
+
+	return &SQLiteStore{db: db}, nil
+}
+
+func (s *SQLiteStore) Add(ctx context.Context, event Event) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx,
+		"INSERT INTO events (time, event, job_id, job_name, project, location, state) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		event.Time.UnixNano(), event.Event, event.JobId, event.JobName, event.Project, event.Location, event.State,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert event: %w", err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	for _, d := range event.Deliveries {
+		_, err := tx.ExecContext(ctx,
+			"INSERT INTO deliveries (event_id, handler, type, success, error) VALUES (?, ?, ?, ?, ?)",
+			id, d.Handler, d.Type, d.Success, d.Error,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert delivery: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) Events(ctx context.Context, query Query) ([]Event, error) {
+	conditions := []string{}
+	args := []any{}
+
+	if query.JobId != "" {
+		conditions = append(conditions, "job_id = ?")
+		args = append(args, query.JobId)
+	}
+
+	if query.Event != "" {
+		conditions = append(conditions, "event = ?")
+		args = append(args, query.Event)
+	}
+
+	if !query.Since.IsZero() {
+		conditions = append(conditions, "time >= ?")
+		args = append(args, query.Since.UnixNano())
+	}
+
+	stmt := "SELECT id, time, event, job_id, job_name, project, location, state FROM events"
+	if len(conditions) > 0 {
+		stmt += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	stmt += " ORDER BY time DESC, id DESC"
+
+	if query.Limit > 0 {
+		stmt += " LIMIT ?"
+		args = append(args, query.Limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []Event{}
+	for rows.Next() {
+		var event Event
+		var nanos int64
+
+		err := rows.Scan(&event.Id, &nanos, &event.Event, &event.JobId, &event.JobName, &event.Project, &event.Location, &event.State)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read event: %w", err)
+		}
+
+		event.Time = time.Unix(0, nanos).UTC()
+		event.Deliveries = []Delivery{}
+		events = append(events, event)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read events: %w", err)
+	}
+
+	// the connection is released before the deliveries are queried
+	rows.Close()
+
+	for i := range events {
+		events[i].Deliveries, err = s.deliveries(ctx, events[i].Id)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return events, nil
+}
+
+func (s *SQLiteStore) deliveries(ctx context.Context, eventId int64) ([]Delivery, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT handler, type, success, error FROM deliveries WHERE event_id = ? ORDER BY rowid", eventId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []Delivery{}
+	for rows.Next() {
+		var d Delivery
+
+		err := rows.Scan(&d.Handler, &d.Type, &d.Success, &d.Error)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read delivery: %w", err)
+		}
+
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, rows.Err()
+}
+
+func (s *SQLiteStore) Prune(ctx context.Context, before time.Time) (int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM deliveries WHERE event_id IN (SELECT id FROM events WHERE time < ?)", before.UnixNano())
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete deliveries: %w", err)
+	}
+
+	res, err := tx.ExecContext(ctx, "DELETE FROM events WHERE time < ?", before.UnixNano())
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete events: %w", err)
+	}
+
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return deleted, tx.Commit()
+}
+
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
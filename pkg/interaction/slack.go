@@ -0,0 +1,216 @@
+package interaction
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+	"github.com/yannickalex07/dmon/pkg/dataflow"
+	"github.com/yannickalex07/dmon/pkg/handler"
+	"github.com/yannickalex07/dmon/pkg/monitor"
+	"github.com/yannickalex07/dmon/pkg/storage"
+)
+
+// SnoozeDuration is how long the alerts of a job are muted by the snooze button.
+const SnoozeDuration time.Duration = time.Hour
+
+// maxPayloadSize limits the size of the requests that are read.
+const maxPayloadSize int64 = 1 << 20
+
+// actionTimeout limits how long an action may take, since it runs after Slack received its response.
+const actionTimeout time.Duration = 30 * time.Second
+
+// defaultClient updates the messages if no client is set.
+var defaultClient = &http.Client{Timeout: 10 * time.Second}
+
+// SlackActions handles the interactive buttons of Slack messages. Every request
+// is verified with the signing secret of the Slack app. After an action was taken,
+// the message is updated to show who took it.
+type SlackActions struct {
+	SigningSecret string
+	Dataflow      dataflow.Dataflow
+
+	// Store is used to snooze jobs, without a store snoozing is not supported.
+	Store storage.ValueStorage
+
+	// Client is used to update the messages, defaults to a client with a timeout of 10 seconds.
+	Client *http.Client
+}
+
+func (a SlackActions) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxPayloadSize))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	err = a.verify(r.Header, body)
+	if err != nil {
+		log.Warnf("Rejected Slack interaction: %s", err.Error())
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var callback slack.InteractionCallback
+	err = json.Unmarshal([]byte(form.Get("payload")), &callback)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if callback.Type != slack.InteractionTypeBlockActions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// Slack expects a response within 3 seconds, so the actions are taken afterwards
+	w.WriteHeader(http.StatusOK)
+
+	go a.handleAll(callback)
+}
+
+// handleAll takes the actions of the callback on a context that is detached from the request,
+// which is already answered at this point.
+func (a SlackActions) handleAll(callback slack.InteractionCallback) {
+	ctx, cancel := context.WithTimeout(context.Background(), actionTimeout)
+	defer cancel()
+
+	for _, action := range callback.ActionCallback.BlockActions {
+		err := a.handle(ctx, callback, action)
+		if err != nil {
+			log.Errorf("failed to handle Slack action %s: %s", action.ActionID, err.Error())
+		}
+	}
+}
+
+func (a SlackActions) verify(header http.Header, body []byte) error {
+	if a.SigningSecret == "" {
+		return fmt.Errorf("no signing secret is configured")
+	}
+
+	verifier, err := slack.NewSecretsVerifier(header, a.SigningSecret)
+	if err != nil {
+		return err
+	}
+
+	_, err = verifier.Write(body)
+	if err != nil {
+		return err
+	}
+
+	return verifier.Ensure()
+}
+
+// handle takes the action and updates the message. If the action fails, the
+// user that clicked the button is informed with an ephemeral message.
+func (a SlackActions) handle(ctx context.Context, callback slack.InteractionCallback, action *slack.BlockAction) error {
+	switch action.ActionID {
+	case handler.SlackActionCancel, handler.SlackActionDrain, handler.SlackActionAcknowledge, handler.SlackActionSnooze:
+	default:
+		// i.e. the link to the Dataflow UI
+		return nil
+	}
+
+	job, err := handler.ParseSlackJobReference(action.Value)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("User %s requested %s for job %s", callback.User.Name, action.ActionID, job.Id)
+
+	user := callback.User.ID
+
+	var note string
+	switch action.ActionID {
+	case handler.SlackActionCancel:
+		err = a.Dataflow.UpdateState(ctx, job, dataflow.StateCancelled)
+		note = fmt.Sprintf("🛑 Cancellation requested by <@%s>", user)
+	case handler.SlackActionDrain:
+		err = a.Dataflow.UpdateState(ctx, job, dataflow.StateDrained)
+		note = fmt.Sprintf("🛑 Drain requested by <@%s>", user)
+	case handler.SlackActionAcknowledge:
+		note = fmt.Sprintf("👀 Acknowledged by <@%s>", user)
+	case handler.SlackActionSnooze:
+		if a.Store == nil {
+			err = fmt.Errorf("snoozing requires a storage that can hold values")
+		} else {
+			err = monitor.Snooze(ctx, a.Store, job.Id, SnoozeDuration)
+		}
+
+		note = fmt.Sprintf("💤 Snoozed for %s by <@%s>", SnoozeDuration, user)
+	}
+
+	if err != nil {
+		reply := &slack.WebhookMessage{
+			ResponseType: slack.ResponseTypeEphemeral,
+			Text:         fmt.Sprintf("⚠️ Failed to handle the action for job %s: %s", job.Id, err.Error()),
+		}
+
+		return errors.Join(err, a.respond(ctx, callback.ResponseURL, reply))
+	}
+
+	blocks := a.acknowledgedBlocks(callback.Message.Blocks.BlockSet, note)
+	reply := &slack.WebhookMessage{
+		ReplaceOriginal: true,
+		Blocks:          &slack.Blocks{BlockSet: blocks},
+	}
+
+	// the handler rebuilds the message from these blocks when it updates the header
+	var storeErr error
+	if a.Store != nil && callback.Channel.ID != "" {
+		storeErr = handler.StoreSlackMessage(ctx, a.Store, callback.Channel.ID, callback.Message.Timestamp, blocks)
+	}
+
+	return errors.Join(storeErr, a.respond(ctx, callback.ResponseURL, reply))
+}
+
+// acknowledgedBlocks removes the interactive buttons from the message and adds a note.
+func (a SlackActions) acknowledgedBlocks(original []slack.Block, note string) []slack.Block {
+	blocks := make([]slack.Block, 0, len(original)+1)
+	for _, block := range original {
+		if actions, ok := block.(*slack.ActionBlock); ok && actions.BlockID == handler.SlackActionsBlockId {
+			continue
+		}
+
+		blocks = append(blocks, block)
+	}
+
+	text := fmt.Sprintf("%s at %s", note, time.Now().UTC().Format(time.RFC1123))
+	return append(blocks, slack.NewContextBlock("dmon-action-note", slack.NewTextBlockObject("mrkdwn", text, false, false)))
+}
+
+func (a SlackActions) respond(ctx context.Context, responseUrl string, msg *slack.WebhookMessage) error {
+	if responseUrl == "" {
+		return fmt.Errorf("the interaction has no response url")
+	}
+
+	client := a.Client
+	if client == nil {
+		client = defaultClient
+	}
+
+	err := slack.PostWebhookCustomHTTPContext(ctx, responseUrl, client, msg)
+	if err != nil {
+		return fmt.Errorf("failed to update message with error: %w", err)
+	}
+
+	return nil
+}
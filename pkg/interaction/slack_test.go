@@ -0,0 +1,256 @@
+package interaction_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yannickalex07/dmon/pkg/dataflow"
+	"github.com/yannickalex07/dmon/pkg/handler"
+	"github.com/yannickalex07/dmon/pkg/interaction"
+	"github.com/yannickalex07/dmon/pkg/model"
+	"github.com/yannickalex07/dmon/pkg/storage"
+	"google.golang.org/api/option"
+)
+
+const signingSecret = "my-signing-secret"
+
+// FAKES
+
+type FakeApi struct {
+	// Updates are the states that were requested through the Dataflow API, per path.
+	Updates map[string]string
+
+	// Responses are the messages that were sent to the response url.
+	Responses []map[string]interface{}
+
+	// responded receives a value for every message that was sent to the response url.
+	responded chan struct{}
+}
+
+// waitForResponse waits until a message was sent to the response url, since
+// the actions are taken after the request was answered.
+func (a *FakeApi) waitForResponse(t *testing.T) {
+	select {
+	case <-a.responded:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no message was sent to the response url")
+	}
+}
+
+// newFakeApi serves a fake Dataflow API and the response url of Slack.
+func newFakeApi(t *testing.T) (*httptest.Server, *FakeApi) {
+	api := &FakeApi{Updates: map[string]string{}, responded: make(chan struct{}, 10)}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/response" {
+			var msg map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&msg)
+			api.Responses = append(api.Responses, msg)
+
+			w.WriteHeader(http.StatusOK)
+			api.responded <- struct{}{}
+			return
+		}
+
+		var job struct {
+			RequestedState string `json:"requestedState"`
+		}
+		json.NewDecoder(r.Body).Decode(&job)
+		api.Updates[r.URL.Path] = job.RequestedState
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`))
+	}))
+
+	t.Cleanup(server.Close)
+
+	return server, api
+}
+
+func newActions(server *httptest.Server) interaction.SlackActions {
+	return interaction.SlackActions{
+		SigningSecret: signingSecret,
+		Dataflow: dataflow.MultiClient{Clients: []dataflow.DataflowClient{{
+			Project: "my-project",
+			Options: []option.ClientOption{option.WithEndpoint(server.URL), option.WithoutAuthentication()},
+		}}},
+		Store: storage.NewMemoryStore(time.Hour),
+	}
+}
+
+// newPayload creates the payload of a click on the button with the action id.
+func newPayload(server *httptest.Server, actionId string) string {
+	payload := map[string]interface{}{
+		"type":         "block_actions",
+		"user":         map[string]string{"id": "U123", "name": "jane"},
+		"response_url": server.URL + "/response",
+		"message": map[string]interface{}{
+			"blocks": []map[string]interface{}{
+				{"type": "header", "text": map[string]interface{}{"type": "plain_text", "text": "⚠️ Job Timeout"}},
+				{"type": "actions", "block_id": handler.SlackActionsBlockId, "elements": []map[string]interface{}{
+					{"type": "button", "action_id": actionId, "text": map[string]interface{}{"type": "plain_text", "text": "Click"}},
+				}},
+			},
+		},
+		"actions": []map[string]interface{}{
+			{"type": "button", "action_id": actionId, "block_id": handler.SlackActionsBlockId, "value": handler.SlackJobReference("my-project", "europe-west1", "my-job-id")},
+		},
+	}
+
+	body, _ := json.Marshal(payload)
+	return url.Values{"payload": {string(body)}}.Encode()
+}
+
+// newSignedRequest signs the body like Slack does with the signing secret.
+func newSignedRequest(body string, secret string) *http.Request {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	io.WriteString(mac, fmt.Sprintf("v0:%s:%s", timestamp, body))
+
+	req := httptest.NewRequest(http.MethodPost, "/slack/actions", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Slack-Request-Timestamp", timestamp)
+	req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+
+	return req
+}
+
+// BlockingDataflow blocks every state update until it is released.
+type BlockingDataflow struct {
+	dataflow.MultiClient
+
+	Release chan struct{}
+}
+
+func (b BlockingDataflow) UpdateState(ctx context.Context, job model.Job, state string) error {
+	<-b.Release
+	return nil
+}
+
+// TESTS
+
+func TestSlackActionsCancelJob(t *testing.T) {
+	// - Arrange
+	server, api := newFakeApi(t)
+	actions := newActions(server)
+
+	req := newSignedRequest(newPayload(server, handler.SlackActionCancel), signingSecret)
+	res := httptest.NewRecorder()
+
+	// - Act
+	actions.ServeHTTP(res, req)
+	api.waitForResponse(t)
+
+	// - Assert
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, map[string]string{
+		"/v1b3/projects/my-project/locations/europe-west1/jobs/my-job-id": "JOB_STATE_CANCELLED",
+	}, api.Updates)
+
+	assert.Len(t, api.Responses, 1)
+	assert.Equal(t, true, api.Responses[0]["replace_original"])
+
+	blocks, _ := json.Marshal(api.Responses[0]["blocks"])
+	assert.NotContains(t, string(blocks), handler.SlackActionsBlockId) // -> buttons are removed
+	assert.Contains(t, string(blocks), "Cancellation requested by")
+	assert.Contains(t, string(blocks), "@U123")
+}
+
+func TestSlackActionsSnoozeJob(t *testing.T) {
+	// - Arrange
+	server, api := newFakeApi(t)
+	actions := newActions(server)
+
+	req := newSignedRequest(newPayload(server, handler.SlackActionSnooze), signingSecret)
+	res := httptest.NewRecorder()
+
+	// - Act
+	actions.ServeHTTP(res, req)
+	api.waitForResponse(t)
+
+	// - Assert
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Empty(t, api.Updates) // -> the job is not touched
+
+	assert.Len(t, api.Responses, 1)
+	blocks, _ := json.Marshal(api.Responses[0]["blocks"])
+	assert.Contains(t, string(blocks), "Snoozed for 1h0m0s")
+}
+
+// This test asserts that requests that are not signed with the
+// signing secret are rejected without taking any action.
+func TestSlackActionsRejectsInvalidSignature(t *testing.T) {
+	// - Arrange
+	server, api := newFakeApi(t)
+	actions := newActions(server)
+
+	req := newSignedRequest(newPayload(server, handler.SlackActionCancel), "another-secret")
+	res := httptest.NewRecorder()
+
+	// - Act
+	actions.ServeHTTP(res, req)
+
+	// - Assert
+	assert.Equal(t, http.StatusUnauthorized, res.Code)
+	assert.Empty(t, api.Updates)
+	assert.Empty(t, api.Responses)
+}
+
+// This test asserts that the user is informed if the job could not be updated.
+func TestSlackActionsReportsFailedUpdate(t *testing.T) {
+	// - Arrange
+	server, api := newFakeApi(t)
+	actions := newActions(server)
+	actions.Dataflow = dataflow.MultiClient{} // -> no client for the project
+
+	req := newSignedRequest(newPayload(server, handler.SlackActionDrain), signingSecret)
+	res := httptest.NewRecorder()
+
+	// - Act
+	actions.ServeHTTP(res, req)
+	api.waitForResponse(t)
+
+	// - Assert
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Len(t, api.Responses, 1)
+	assert.Equal(t, "ephemeral", api.Responses[0]["response_type"])
+	assert.Equal(t, false, api.Responses[0]["replace_original"])
+}
+
+// This test asserts that Slack receives its response before the action is taken,
+// since Slack expects a response within 3 seconds.
+func TestSlackActionsRespondsBeforeTakingAction(t *testing.T) {
+	// - Arrange
+	server, api := newFakeApi(t)
+	actions := newActions(server)
+
+	blocking := BlockingDataflow{Release: make(chan struct{})}
+	actions.Dataflow = blocking
+
+	req := newSignedRequest(newPayload(server, handler.SlackActionCancel), signingSecret)
+	res := httptest.NewRecorder()
+
+	// - Act
+	actions.ServeHTTP(res, req)
+
+	// - Assert
+	assert.Equal(t, http.StatusOK, res.Code)
+
+	close(blocking.Release)
+	api.waitForResponse(t)
+	assert.Equal(t, true, api.Responses[0]["replace_original"])
+}
@@ -0,0 +1,86 @@
+package lock
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Elector keeps track of whether this instance is the leader by periodically
+// acquiring or renewing the lease of a Lock.
+type Elector struct {
+	Lock          Lock
+	LeaseDuration time.Duration
+
+	mu       sync.Mutex
+	isLeader bool
+}
+
+// IsLeader reports if this instance held the lease during the last renewal.
+func (e *Elector) IsLeader() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.isLeader
+}
+
+// Renew acquires or renews the lease and reports whether this instance is the leader.
+// If the lease can't be acquired because of an error, leadership is given up to
+// not risk two instances acting as leader at the same time.
+func (e *Elector) Renew(ctx context.Context) bool {
+	acquired, err := e.Lock.Acquire(ctx, e.LeaseDuration)
+	if err != nil {
+		log.Errorf("failed to acquire leader lease: %s", err.Error())
+		acquired = false
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if acquired && !e.isLeader {
+		log.Info("This instance became the leader.")
+	}
+
+	if !acquired && e.isLeader {
+		log.Warn("This instance stopped being the leader.")
+	}
+
+	e.isLeader = acquired
+
+	return acquired
+}
+
+// Run renews the lease in the background until the context is canceled.
+// The lease is renewed three times per lease duration so that a single failed
+// renewal does not lead to a loss of leadership.
+func (e *Elector) Run(ctx context.Context) {
+	e.Renew(ctx)
+
+	ticker := time.NewTicker(e.LeaseDuration / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			e.Renew(ctx)
+		}
+	}
+}
+
+// Release gives up the lease, so that another instance can take over immediately.
+func (e *Elector) Release(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.isLeader {
+		log.Info("Releasing leadership.")
+	}
+
+	e.isLeader = false
+
+	return e.Lock.Release(ctx)
+}
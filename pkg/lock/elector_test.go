@@ -0,0 +1,99 @@
+package lock_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yannickalex07/dmon/pkg/lock"
+)
+
+// FAKES
+
+type FakeLock struct {
+	Acquired     bool
+	AcquireError error
+
+	Released bool
+}
+
+func (f *FakeLock) Acquire(ctx context.Context, ttl time.Duration) (bool, error) {
+	return f.Acquired, f.AcquireError
+}
+
+func (f *FakeLock) Release(ctx context.Context) error {
+	f.Released = true
+	return nil
+}
+
+// TESTS
+
+func TestElectorRenew(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+
+	fakeLock := &FakeLock{Acquired: true}
+	elector := lock.Elector{
+		Lock:          fakeLock,
+		LeaseDuration: 1 * time.Minute,
+	}
+
+	// - Act
+	becameLeader := elector.Renew(ctx)
+	isLeader := elector.IsLeader()
+
+	fakeLock.Acquired = false // -> another instance took over the lease
+	stillLeader := elector.Renew(ctx)
+
+	// - Assert
+	assert.True(t, becameLeader)
+	assert.True(t, isLeader)
+	assert.False(t, stillLeader)
+	assert.False(t, elector.IsLeader())
+}
+
+// This test asserts that the elector gives up leadership
+// when the lease can't be renewed because of an error.
+func TestElectorRenewWithError(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+
+	fakeLock := &FakeLock{Acquired: true}
+	elector := lock.Elector{
+		Lock:          fakeLock,
+		LeaseDuration: 1 * time.Minute,
+	}
+
+	elector.Renew(ctx)
+
+	// - Act
+	fakeLock.AcquireError = errors.New("error")
+	isLeader := elector.Renew(ctx)
+
+	// - Assert
+	assert.False(t, isLeader)
+	assert.False(t, elector.IsLeader())
+}
+
+func TestElectorRelease(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+
+	fakeLock := &FakeLock{Acquired: true}
+	elector := lock.Elector{
+		Lock:          fakeLock,
+		LeaseDuration: 1 * time.Minute,
+	}
+
+	elector.Renew(ctx)
+
+	// - Act
+	err := elector.Release(ctx)
+
+	// - Assert
+	assert.Nil(t, err)
+	assert.True(t, fakeLock.Released)
+	assert.False(t, elector.IsLeader())
+}
@@ -0,0 +1,144 @@
+package lock
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type fileLease struct {
+	Holder    string    `json:"holder"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// FileLock stores the lease in a file. It is meant for instances that share
+// the same filesystem, for replicas on different machines prefer the RedisLock.
+type FileLock struct {
+	path     string
+	identity string
+}
+
+func NewFileLock(path string, identity string) *FileLock {
+	return &FileLock{
+		path:     path,
+		identity: identity,
+	}
+}
+
+func (l FileLock) Acquire(ctx context.Context, ttl time.Duration) (bool, error) {
+	acquired := false
+
+	err := l.exclusive(func() error {
+		lease, err := l.read()
+		if err != nil {
+			return err
+		}
+
+		// somebody else holds a valid lease
+		if lease != nil && lease.Holder != l.identity && time.Now().Before(lease.ExpiresAt) {
+			return nil
+		}
+
+		err = l.write(fileLease{
+			Holder:    l.identity,
+			ExpiresAt: time.Now().Add(ttl).UTC(),
+		})
+		if err != nil {
+			return err
+		}
+
+		acquired = true
+		return nil
+	})
+
+	return acquired, err
+}
+
+func (l FileLock) Release(ctx context.Context) error {
+	return l.exclusive(func() error {
+		lease, err := l.read()
+		if err != nil {
+			return err
+		}
+
+		if lease == nil || lease.Holder != l.identity {
+			return nil
+		}
+
+		err = os.Remove(l.path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove lease file: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// exclusive runs the function while holding an exclusive lock on a file next to the
+// lease file, so that instances can't read and take over an expired lease at the same time.
+func (l FileLock) exclusive(f func() error) error {
+	file, err := os.OpenFile(l.path+".flock", os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open lock file: %w", err)
+	}
+	defer file.Close()
+
+	err = lockFile(file)
+	if err != nil {
+		return fmt.Errorf("failed to lock lock file: %w", err)
+	}
+	defer unlockFile(file)
+
+	return f()
+}
+
+func (l FileLock) read() (*fileLease, error) {
+	content, err := os.ReadFile(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lease file: %w", err)
+	}
+
+	var lease fileLease
+	err = json.Unmarshal(content, &lease)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode lease file: %w", err)
+	}
+
+	return &lease, nil
+}
+
+func (l FileLock) write(lease fileLease) error {
+	content, err := json.Marshal(lease)
+	if err != nil {
+		return fmt.Errorf("failed to encode lease: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(l.path), filepath.Base(l.path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary lease file: %w", err)
+	}
+
+	_, err = tmp.Write(content)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = os.Rename(tmp.Name(), l.path)
+	}
+
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write lease file: %w", err)
+	}
+
+	return nil
+}
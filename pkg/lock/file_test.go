@@ -0,0 +1,155 @@
+package lock_test
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yannickalex07/dmon/pkg/lock"
+)
+
+func TestFileLockAcquireWithFreeLease(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "leader.lock")
+
+	l := lock.NewFileLock(path, "instance-1")
+
+	// - Act
+	acquired, err := l.Acquire(ctx, 1*time.Minute)
+
+	// - Assert
+	assert.Nil(t, err)
+	assert.True(t, acquired)
+}
+
+func TestFileLockAcquireWithLeaseHeldByOtherInstance(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "leader.lock")
+
+	first := lock.NewFileLock(path, "instance-1")
+	second := lock.NewFileLock(path, "instance-2")
+
+	// - Act
+	firstAcquired, firstErr := first.Acquire(ctx, 1*time.Minute)
+	secondAcquired, secondErr := second.Acquire(ctx, 1*time.Minute)
+	renewed, renewErr := first.Acquire(ctx, 1*time.Minute)
+
+	// - Assert
+	assert.Nil(t, firstErr)
+	assert.Nil(t, secondErr)
+	assert.Nil(t, renewErr)
+
+	assert.True(t, firstAcquired)
+	assert.False(t, secondAcquired)
+	assert.True(t, renewed)
+}
+
+func TestFileLockAcquireWithExpiredLease(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "leader.lock")
+
+	first := lock.NewFileLock(path, "instance-1")
+	second := lock.NewFileLock(path, "instance-2")
+
+	// - Act
+	_, firstErr := first.Acquire(ctx, 1*time.Millisecond)
+
+	time.Sleep(10 * time.Millisecond)
+
+	secondAcquired, secondErr := second.Acquire(ctx, 1*time.Minute)
+	firstAcquired, renewErr := first.Acquire(ctx, 1*time.Minute)
+
+	// - Assert
+	assert.Nil(t, firstErr)
+	assert.Nil(t, secondErr)
+	assert.Nil(t, renewErr)
+
+	assert.True(t, secondAcquired)
+	assert.False(t, firstAcquired)
+}
+
+func TestFileLockRelease(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "leader.lock")
+
+	first := lock.NewFileLock(path, "instance-1")
+	second := lock.NewFileLock(path, "instance-2")
+
+	_, err := first.Acquire(ctx, 1*time.Minute)
+	assert.Nil(t, err)
+
+	// - Act
+	foreignReleaseErr := second.Release(ctx) // -> should not release the lease of instance-1
+	blocked, blockedErr := second.Acquire(ctx, 1*time.Minute)
+
+	releaseErr := first.Release(ctx)
+	acquired, acquireErr := second.Acquire(ctx, 1*time.Minute)
+
+	// - Assert
+	assert.Nil(t, foreignReleaseErr)
+	assert.Nil(t, blockedErr)
+	assert.Nil(t, releaseErr)
+	assert.Nil(t, acquireErr)
+
+	assert.False(t, blocked)
+	assert.True(t, acquired)
+}
+
+// This test asserts that only one instance acquires an expired lease,
+// even if multiple instances try to take it over at the same time.
+func TestFileLockAcquireConcurrently(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "leader.lock")
+
+	instances := []*lock.FileLock{}
+	for i := 0; i < 8; i++ {
+		instances = append(instances, lock.NewFileLock(path, fmt.Sprintf("instance-%d", i)))
+	}
+
+	for i := 0; i < 20; i++ {
+		// the lease of another instance is expired, so every instance may take it over
+		expired := lock.NewFileLock(path, "expired")
+		_, err := expired.Acquire(ctx, -1*time.Minute)
+		assert.Nil(t, err)
+
+		start := make(chan struct{})
+		results := make(chan bool, len(instances))
+		var wg sync.WaitGroup
+
+		for _, l := range instances {
+			wg.Add(1)
+			go func(l *lock.FileLock) {
+				defer wg.Done()
+				<-start
+
+				acquired, err := l.Acquire(ctx, 1*time.Minute)
+				assert.Nil(t, err)
+				results <- acquired
+			}(l)
+		}
+
+		// - Act
+		close(start)
+		wg.Wait()
+		close(results)
+
+		// - Assert
+		acquired := 0
+		for result := range results {
+			if result {
+				acquired++
+			}
+		}
+
+		assert.Equal(t, 1, acquired)
+	}
+}
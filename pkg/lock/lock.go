@@ -0,0 +1,19 @@
+package lock
+
+import (
+	"context"
+	"time"
+)
+
+// Lock is a lease that can only be held by a single instance at a time.
+// It follows the semantics of a Kubernetes Lease: a holder acquires the lease
+// for a given duration and has to renew it before it expires, otherwise another
+// instance is allowed to take it over.
+type Lock interface {
+	// Acquire acquires the lease or renews it if it is already held by this
+	// instance. It reports whether this instance holds the lease afterwards.
+	Acquire(ctx context.Context, ttl time.Duration) (bool, error)
+
+	// Release gives up the lease if it is held by this instance.
+	Release(ctx context.Context) error
+}
@@ -0,0 +1,68 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// renews the lease if it is held by the given identity
+var renewScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// deletes the lease if it is held by the given identity
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// RedisLock stores the lease as a Redis key that expires after the lease duration.
+type RedisLock struct {
+	client   *redis.Client
+	key      string
+	identity string
+}
+
+func NewRedisLock(client *redis.Client, key string, identity string) *RedisLock {
+	return &RedisLock{
+		client:   client,
+		key:      key,
+		identity: identity,
+	}
+}
+
+func (l RedisLock) Acquire(ctx context.Context, ttl time.Duration) (bool, error) {
+	acquired, err := l.client.SetNX(ctx, l.key, l.identity, ttl).Result()
+	if err != nil {
+		return false, err
+	}
+
+	if acquired {
+		return true, nil
+	}
+
+	// the lease already exists, so we try to renew it in case we are the holder
+	renewed, err := renewScript.Run(ctx, l.client, []string{l.key}, l.identity, ttl.Milliseconds()).Int()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return false, err
+	}
+
+	return renewed == 1, nil
+}
+
+func (l RedisLock) Release(ctx context.Context) error {
+	err := releaseScript.Run(ctx, l.client, []string{l.key}, l.identity).Err()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return err
+	}
+
+	return nil
+}
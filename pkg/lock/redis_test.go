@@ -0,0 +1,97 @@
+package lock_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/yannickalex07/dmon/pkg/lock"
+)
+
+func newRedisClient(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+
+	t.Cleanup(func() { client.Close() })
+
+	return server, client
+}
+
+func TestRedisLockAcquireWithLeaseHeldByOtherInstance(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	_, client := newRedisClient(t)
+
+	first := lock.NewRedisLock(client, "dmon:leader", "instance-1")
+	second := lock.NewRedisLock(client, "dmon:leader", "instance-2")
+
+	// - Act
+	firstAcquired, firstErr := first.Acquire(ctx, 1*time.Minute)
+	secondAcquired, secondErr := second.Acquire(ctx, 1*time.Minute)
+	renewed, renewErr := first.Acquire(ctx, 1*time.Minute)
+
+	// - Assert
+	assert.Nil(t, firstErr)
+	assert.Nil(t, secondErr)
+	assert.Nil(t, renewErr)
+
+	assert.True(t, firstAcquired)
+	assert.False(t, secondAcquired)
+	assert.True(t, renewed)
+}
+
+func TestRedisLockAcquireWithExpiredLease(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	server, client := newRedisClient(t)
+
+	first := lock.NewRedisLock(client, "dmon:leader", "instance-1")
+	second := lock.NewRedisLock(client, "dmon:leader", "instance-2")
+
+	// - Act
+	_, firstErr := first.Acquire(ctx, 1*time.Minute)
+
+	server.FastForward(2 * time.Minute)
+
+	secondAcquired, secondErr := second.Acquire(ctx, 1*time.Minute)
+	firstAcquired, renewErr := first.Acquire(ctx, 1*time.Minute)
+
+	// - Assert
+	assert.Nil(t, firstErr)
+	assert.Nil(t, secondErr)
+	assert.Nil(t, renewErr)
+
+	assert.True(t, secondAcquired)
+	assert.False(t, firstAcquired)
+}
+
+func TestRedisLockRelease(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	_, client := newRedisClient(t)
+
+	first := lock.NewRedisLock(client, "dmon:leader", "instance-1")
+	second := lock.NewRedisLock(client, "dmon:leader", "instance-2")
+
+	_, err := first.Acquire(ctx, 1*time.Minute)
+	assert.Nil(t, err)
+
+	// - Act
+	foreignReleaseErr := second.Release(ctx) // -> should not release the lease of instance-1
+	blocked, blockedErr := second.Acquire(ctx, 1*time.Minute)
+
+	releaseErr := first.Release(ctx)
+	acquired, acquireErr := second.Acquire(ctx, 1*time.Minute)
+
+	// - Assert
+	assert.Nil(t, foreignReleaseErr)
+	assert.Nil(t, blockedErr)
+	assert.Nil(t, releaseErr)
+	assert.Nil(t, acquireErr)
+
+	assert.False(t, blocked)
+	assert.True(t, acquired)
+}
@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/yannickalex07/dmon/pkg/dataflow"
+	"github.com/yannickalex07/dmon/pkg/model"
+)
+
+// Dataflow counts the failed requests of the wrapped client.
+type Dataflow struct {
+	Client dataflow.Dataflow
+}
+
+func (d Dataflow) Jobs(ctx context.Context) ([]model.Job, error) {
+	jobs, err := d.Client.Jobs(ctx)
+	return jobs, d.record("jobs", err)
+}
+
+func (d Dataflow) ErrorLogs(ctx context.Context, job model.Job) ([]model.LogEntry, error) {
+	entries, err := d.Client.ErrorLogs(ctx, job)
+	return entries, d.record("error_logs", err)
+}
+
+func (d Dataflow) StreamingMetrics(ctx context.Context, job model.Job) (model.StreamingMetrics, error) {
+	metrics, err := d.Client.StreamingMetrics(ctx, job)
+	return metrics, d.record("streaming_metrics", err)
+}
+
+func (d Dataflow) UpdateState(ctx context.Context, job model.Job, state string) error {
+	return d.record("update_state", d.Client.UpdateState(ctx, job, state))
+}
+
+func (d Dataflow) LaunchTemplate(ctx context.Context, job model.Job, launch model.TemplateLaunch) (string, error) {
+	id, err := d.Client.LaunchTemplate(ctx, job, launch)
+	return id, d.record("launch_template", err)
+}
+
+func (d Dataflow) record(operation string, err error) error {
+	if err != nil {
+		RecordDataflowError(operation)
+	}
+
+	return err
+}
@@ -0,0 +1,10 @@
+package metrics
+
+// The counters are exported for the tests, they are never reset and
+// accumulate across repeated test runs.
+var (
+	MonitorRuns    = monitorRuns
+	DataflowErrors = dataflowErrors
+	HandlerCalls   = handlerCalls
+	Notifications  = notifications
+)
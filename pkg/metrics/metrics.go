@@ -0,0 +1,121 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/yannickalex07/dmon/pkg/model"
+)
+
+// Registry contains the metrics of dmon and of the observed jobs.
+var Registry = prometheus.NewRegistry()
+
+var (
+	monitorRuns = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "dmon_monitor_runs_total",
+		Help: "Number of monitor runs by result.",
+	}, []string{"result"})
+
+	dataflowErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "dmon_dataflow_api_errors_total",
+		Help: "Number of failed requests to the Dataflow API by operation.",
+	}, []string{"operation"})
+
+	handlerCalls = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "dmon_handler_calls_total",
+		Help: "Number of events that were passed to handlers by handler type, event and result.",
+	}, []string{"type", "event", "result"})
+
+	notifications = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "dmon_notifications_total",
+		Help: "Number of events that were sent through at least one handler by event.",
+	}, []string{"event"})
+
+	jobs = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "dmon_jobs",
+		Help: "Number of monitored jobs by state during the last run.",
+	}, []string{"state"})
+
+	jobRuntime = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "dmon_job_runtime_seconds",
+		Help: "Runtime of the running jobs during the last run.",
+	}, []string{"project", "job_id", "job_name", "job_type"})
+
+	lastSuccessfulRun = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "dmon_last_successful_run_timestamp_seconds",
+		Help: "Unix timestamp of the last monitor run that succeeded.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		monitorRuns,
+		dataflowErrors,
+		handlerCalls,
+		notifications,
+		jobs,
+		jobRuntime,
+		lastSuccessfulRun,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// RecordRun counts a monitor run, successful runs also update the timestamp of the last successful run.
+func RecordRun(err error) {
+	if err != nil {
+		monitorRuns.WithLabelValues("failure").Inc()
+		return
+	}
+
+	monitorRuns.WithLabelValues("success").Inc()
+	lastSuccessfulRun.Set(float64(time.Now().Unix()))
+}
+
+// RecordPartialRun counts a monitor run that could not list the jobs of some projects.
+// The timestamp of the last successful run is not updated.
+func RecordPartialRun() {
+	monitorRuns.WithLabelValues("partial").Inc()
+}
+
+// RecordDataflowError counts a failed request to the Dataflow API.
+func RecordDataflowError(operation string) {
+	dataflowErrors.WithLabelValues(operation).Inc()
+}
+
+// RecordHandlerCall counts an event that was passed to a handler of the given type.
+func RecordHandlerCall(handlerType string, event string, err error) {
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+
+	handlerCalls.WithLabelValues(handlerType, event, result).Inc()
+}
+
+// RecordNotification counts an event that was sent through at least one handler.
+func RecordNotification(event string) {
+	notifications.WithLabelValues(event).Inc()
+}
+
+// ObserveJobs replaces the job gauges with the jobs of the current run.
+func ObserveJobs(listed []model.Job) {
+	jobs.Reset()
+	jobRuntime.Reset()
+
+	for _, job := range listed {
+		jobs.WithLabelValues(job.Status.Status).Inc()
+
+		if job.Status.IsRunning() {
+			jobRuntime.WithLabelValues(job.Project, job.Id, job.Name, job.Type).Set(job.Runtime().Seconds())
+		}
+	}
+}
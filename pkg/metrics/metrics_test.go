@@ -0,0 +1,130 @@
+package metrics_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/yannickalex07/dmon/pkg/metrics"
+	"github.com/yannickalex07/dmon/pkg/model"
+)
+
+// FAKES
+
+type FakeDataflow struct {
+	Err error
+}
+
+func (f FakeDataflow) Jobs(ctx context.Context) ([]model.Job, error) {
+	return nil, f.Err
+}
+
+func (f FakeDataflow) ErrorLogs(ctx context.Context, job model.Job) ([]model.LogEntry, error) {
+	return nil, f.Err
+}
+
+func (f FakeDataflow) StreamingMetrics(ctx context.Context, job model.Job) (model.StreamingMetrics, error) {
+	return model.StreamingMetrics{}, f.Err
+}
+
+func (f FakeDataflow) UpdateState(ctx context.Context, job model.Job, state string) error {
+	return f.Err
+}
+
+func (f FakeDataflow) LaunchTemplate(ctx context.Context, job model.Job, launch model.TemplateLaunch) (string, error) {
+	return "", f.Err
+}
+
+func scrape(t *testing.T) string {
+	rec := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	body, err := io.ReadAll(rec.Result().Body)
+	assert.Nil(t, err)
+
+	return string(body)
+}
+
+// TESTS
+
+func TestHandlerExposesRecordedMetrics(t *testing.T) {
+	// - Arrange
+	successes := testutil.ToFloat64(metrics.MonitorRuns.WithLabelValues("success"))
+	failures := testutil.ToFloat64(metrics.MonitorRuns.WithLabelValues("failure"))
+	slackCalls := testutil.ToFloat64(metrics.HandlerCalls.WithLabelValues("slack", "timeout", "success"))
+	emailCalls := testutil.ToFloat64(metrics.HandlerCalls.WithLabelValues("email", "timeout", "failure"))
+	notifications := testutil.ToFloat64(metrics.Notifications.WithLabelValues("timeout"))
+
+	// - Act
+	metrics.RecordRun(nil)
+	metrics.RecordRun(errors.New("error"))
+	metrics.RecordHandlerCall("slack", "timeout", nil)
+	metrics.RecordHandlerCall("email", "timeout", errors.New("error"))
+	metrics.RecordNotification("timeout")
+
+	body := scrape(t)
+
+	// - Assert
+	assert.Equal(t, successes+1, testutil.ToFloat64(metrics.MonitorRuns.WithLabelValues("success")))
+	assert.Equal(t, failures+1, testutil.ToFloat64(metrics.MonitorRuns.WithLabelValues("failure")))
+	assert.Equal(t, slackCalls+1, testutil.ToFloat64(metrics.HandlerCalls.WithLabelValues("slack", "timeout", "success")))
+	assert.Equal(t, emailCalls+1, testutil.ToFloat64(metrics.HandlerCalls.WithLabelValues("email", "timeout", "failure")))
+	assert.Equal(t, notifications+1, testutil.ToFloat64(metrics.Notifications.WithLabelValues("timeout")))
+	assert.Contains(t, body, `dmon_monitor_runs_total{result="success"}`)
+	assert.Contains(t, body, `dmon_handler_calls_total{event="timeout",result="failure",type="email"}`)
+	assert.Contains(t, body, `dmon_notifications_total{event="timeout"}`)
+	assert.Contains(t, body, "dmon_last_successful_run_timestamp_seconds")
+	assert.Contains(t, body, "go_goroutines")
+}
+
+func TestObserveJobsReplacesPreviousJobs(t *testing.T) {
+	// - Arrange
+	now := time.Now()
+	running := model.Job{
+		Id:        "running",
+		Name:      "running-job",
+		Project:   "project",
+		Type:      "JOB_TYPE_BATCH",
+		StartTime: now.Add(-time.Minute),
+		Status:    model.Status{Status: "JOB_STATE_RUNNING", UpdatedAt: now},
+	}
+	done := model.Job{Id: "done", Status: model.Status{Status: "JOB_STATE_DONE", UpdatedAt: now}}
+	old := model.Job{Id: "old", Name: "old-job", Status: model.Status{Status: "JOB_STATE_RUNNING", UpdatedAt: now}}
+
+	// - Act
+	metrics.ObserveJobs([]model.Job{old})
+	metrics.ObserveJobs([]model.Job{running, done})
+	body := scrape(t)
+
+	// - Assert
+	assert.Contains(t, body, `dmon_jobs{state="JOB_STATE_RUNNING"} 1`)
+	assert.Contains(t, body, `dmon_jobs{state="JOB_STATE_DONE"} 1`)
+	assert.Contains(t, body, `dmon_job_runtime_seconds{job_id="running",job_name="running-job",job_type="JOB_TYPE_BATCH",project="project"}`)
+	assert.NotContains(t, body, `job_id="old"`)
+	assert.NotContains(t, body, `job_id="done"`)
+}
+
+func TestDataflowCountsErrors(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	working := metrics.Dataflow{Client: FakeDataflow{}}
+	failing := metrics.Dataflow{Client: FakeDataflow{Err: errors.New("error")}}
+
+	jobsErrors := testutil.ToFloat64(metrics.DataflowErrors.WithLabelValues("jobs"))
+	logsErrors := testutil.ToFloat64(metrics.DataflowErrors.WithLabelValues("error_logs"))
+
+	// - Act
+	_, workingErr := working.Jobs(ctx)
+	_, failingErr := failing.Jobs(ctx)
+
+	// - Assert
+	assert.Nil(t, workingErr)
+	assert.Error(t, failingErr)
+	assert.Equal(t, jobsErrors+1, testutil.ToFloat64(metrics.DataflowErrors.WithLabelValues("jobs")))
+	assert.Equal(t, logsErrors, testutil.ToFloat64(metrics.DataflowErrors.WithLabelValues("error_logs")))
+}
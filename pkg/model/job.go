@@ -10,16 +10,101 @@ type Job struct {
 	Type      string
 	Status    Status
 	StartTime time.Time
+	Labels    map[string]string
+
+	// Project and Location of the GCP project that the job runs in.
+	Project  string
+	Location string
+
+	CreateTime time.Time
+
+	// ReplaceJobId is the id of the job that this job replaced during an update,
+	// ReplacedByJobId the id of the job that replaced this job.
+	ReplaceJobId    string
+	ReplacedByJobId string
+
+	SdkVersion  SdkVersion
+	Environment Environment
+	Template    Template
+
+	// MaxRuntime is the runtime limit that applies to the job. It is set by the
+	// monitor and is zero if the job is not checked for timeouts.
+	MaxRuntime time.Duration
+
+	// Retry is set by the monitor for failed jobs that are relaunched.
+	Retry Retry
 }
 
 func (j Job) IsStreaming() bool {
 	return j.Type == "JOB_TYPE_STREAMING"
 }
 
+func (j Job) IsBatch() bool {
+	return j.Type == "JOB_TYPE_BATCH"
+}
+
+// Runtime returns how long the job is running. For jobs that reached a terminal
+// state it is the time between the start and the last state change.
 func (j Job) Runtime() time.Duration {
+	if j.Status.IsTerminal() {
+		return j.Status.UpdatedAt.Sub(j.StartTime)
+	}
+
 	return time.Since(j.StartTime)
 }
 
+// IsReplacement reports if the job replaced another job during an update.
+func (j Job) IsReplacement() bool {
+	return j.ReplaceJobId != ""
+}
+
+// WasReplaced reports if the job was replaced by another job during an update.
+func (j Job) WasReplaced() bool {
+	return j.ReplacedByJobId != ""
+}
+
+// IsTemplate reports if the job was started from a Google provided template.
+func (j Job) IsTemplate() bool {
+	return j.Template.Name != ""
+}
+
+// SDK VERSION
+
+type SdkVersion struct {
+	Version     string
+	DisplayName string
+
+	// SupportStatus is i.e. STABLE, DEPRECATED or UNSUPPORTED.
+	SupportStatus string
+}
+
+func (s SdkVersion) String() string {
+	if s.DisplayName == "" {
+		return s.Version
+	}
+
+	return s.DisplayName + " " + s.Version
+}
+
+// ENVIRONMENT
+
+type Environment struct {
+	WorkerRegion   string
+	WorkerZone     string
+	ServiceAccount string
+	MachineType    string
+	NumWorkers     int64
+}
+
+// TEMPLATE
+
+// Template describes the Google provided template that a job was started from.
+type Template struct {
+	Name    string
+	Type    string
+	Version string
+}
+
 // STATUS
 
 type Status struct {
@@ -42,3 +127,17 @@ func (s Status) IsDone() bool {
 func (s Status) IsRunning() bool {
 	return s.Status == "JOB_STATE_RUNNING"
 }
+
+func (s Status) IsDrained() bool {
+	return s.Status == "JOB_STATE_DRAINED"
+}
+
+// IsTerminal reports if the job reached a state that it will never leave again.
+func (s Status) IsTerminal() bool {
+	switch s.Status {
+	case "JOB_STATE_DONE", "JOB_STATE_FAILED", "JOB_STATE_CANCELLED", "JOB_STATE_UPDATED", "JOB_STATE_DRAINED":
+		return true
+	}
+
+	return false
+}
@@ -25,6 +25,16 @@ func TestJobIsStreaming(t *testing.T) {
 	assert.True(t, job.IsStreaming())
 }
 
+func TestJobIsBatch(t *testing.T) {
+	// - Arrange
+	batch := model.Job{Type: "JOB_TYPE_BATCH"}
+	streaming := model.Job{Type: "JOB_TYPE_STREAMING"}
+
+	// - Assert
+	assert.True(t, batch.IsBatch())
+	assert.False(t, streaming.IsBatch())
+}
+
 func TestJobRuntime(t *testing.T) {
 	// - Arrange
 	job := model.Job{
@@ -98,3 +108,70 @@ func TestStatusIsRunning(t *testing.T) {
 	// - Assert
 	assert.True(t, status.IsRunning())
 }
+
+func TestStatusIsDrained(t *testing.T) {
+	// - Arrange
+	status := model.Status{
+		Status:    "JOB_STATE_DRAINED",
+		UpdatedAt: time.Now(),
+	}
+
+	// - Assert
+	assert.True(t, status.IsDrained())
+}
+
+func TestStatusIsTerminal(t *testing.T) {
+	// - Arrange
+	terminal := []string{"JOB_STATE_DONE", "JOB_STATE_FAILED", "JOB_STATE_CANCELLED", "JOB_STATE_UPDATED", "JOB_STATE_DRAINED"}
+	nonTerminal := []string{"JOB_STATE_RUNNING", "JOB_STATE_PENDING", "JOB_STATE_QUEUED", "JOB_STATE_CANCELLING", "JOB_STATE_DRAINING"}
+
+	// - Assert
+	for _, s := range terminal {
+		assert.True(t, model.Status{Status: s}.IsTerminal(), s)
+	}
+
+	for _, s := range nonTerminal {
+		assert.False(t, model.Status{Status: s}.IsTerminal(), s)
+	}
+}
+
+func TestJobRuntimeOfFinishedJob(t *testing.T) {
+	// - Arrange
+	startTime := time.Now().Add(-5 * time.Hour)
+	job := model.Job{
+		Status: model.Status{
+			Status:    "JOB_STATE_DONE",
+			UpdatedAt: startTime.Add(2 * time.Hour),
+		},
+		StartTime: startTime,
+	}
+
+	// - Act
+	runtime := job.Runtime()
+
+	// - Assert
+	assert.Equal(t, 2*time.Hour, runtime)
+}
+
+func TestJobReplacement(t *testing.T) {
+	// - Arrange
+	replaced := model.Job{Id: "old", ReplacedByJobId: "new"}
+	replacement := model.Job{Id: "new", ReplaceJobId: "old"}
+
+	// - Assert
+	assert.True(t, replaced.WasReplaced())
+	assert.False(t, replaced.IsReplacement())
+
+	assert.True(t, replacement.IsReplacement())
+	assert.False(t, replacement.WasReplaced())
+}
+
+func TestSdkVersionString(t *testing.T) {
+	// - Arrange
+	withName := model.SdkVersion{Version: "2.48.0", DisplayName: "Apache Beam SDK for Java"}
+	withoutName := model.SdkVersion{Version: "2.48.0"}
+
+	// - Assert
+	assert.Equal(t, "Apache Beam SDK for Java 2.48.0", withName.String())
+	assert.Equal(t, "2.48.0", withoutName.String())
+}
@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+// StreamingMetrics are the health metrics of a running streaming job.
+type StreamingMetrics struct {
+	DataWatermarkAge time.Duration
+	SystemLag        time.Duration
+}
+
+// StreamingHealth describes why a streaming job is considered unhealthy.
+type StreamingHealth struct {
+	Metrics StreamingMetrics
+
+	// The thresholds that were checked, zero if a metric is not checked.
+	MaxDataWatermarkAge time.Duration
+	MaxSystemLag        time.Duration
+
+	// ConsecutiveChecks is the number of checks in a row that found the job unhealthy.
+	ConsecutiveChecks int
+}
+
+// IsUnhealthy reports if any of the metrics exceeds its threshold.
+func (h StreamingHealth) IsUnhealthy() bool {
+	if h.MaxDataWatermarkAge > 0 && h.Metrics.DataWatermarkAge > h.MaxDataWatermarkAge {
+		return true
+	}
+
+	if h.MaxSystemLag > 0 && h.Metrics.SystemLag > h.MaxSystemLag {
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// Actions that dmon can take for jobs that exceed their hard limit.
+const (
+	RemediationCancel string = "cancel"
+	RemediationDrain  string = "drain"
+)
+
+// Remediation describes the action that was taken for a job that exceeded its hard limit.
+type Remediation struct {
+	Action    string
+	HardLimit time.Duration
+
+	// DryRun is set if the action was only logged and not taken.
+	DryRun bool
+
+	// Error is set if the action failed.
+	Error string
+}
+
+func (r Remediation) Failed() bool {
+	return r.Error != ""
+}
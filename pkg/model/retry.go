@@ -0,0 +1,55 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// Retry describes the relaunch of a failed job.
+type Retry struct {
+	Attempt     int
+	MaxAttempts int
+
+	// JobId is the id of the relaunched job, it is empty if the relaunch is scheduled for later.
+	JobId       string
+	ScheduledAt time.Time
+
+	// Exhausted is set if the job was not relaunched because all retries were used.
+	Exhausted bool
+
+	// Error is set if the relaunch failed.
+	Error string
+}
+
+// String describes the retry, i.e. "retry 2/3 launched as job <id>".
+// It is empty if the job is not retried.
+func (r Retry) String() string {
+	switch {
+	case r.MaxAttempts == 0:
+		return ""
+	case r.Exhausted:
+		return fmt.Sprintf("all %d retries used", r.MaxAttempts)
+	case r.Error != "":
+		return fmt.Sprintf("retry %d/%d failed to launch: %s", r.Attempt, r.MaxAttempts, r.Error)
+	case r.JobId != "":
+		return fmt.Sprintf("retry %d/%d launched as job %s", r.Attempt, r.MaxAttempts, r.JobId)
+	default:
+		return fmt.Sprintf("retry %d/%d scheduled for %s", r.Attempt, r.MaxAttempts, r.ScheduledAt.Format(time.RFC1123))
+	}
+}
+
+// TemplateLaunch describes how a job is launched again from its template.
+type TemplateLaunch struct {
+	// Path is the Cloud Storage path of the template, for Flex templates
+	// the path of the container spec.
+	Path string
+	Flex bool
+
+	// Parameters are the names of the pipeline options of the job that are passed
+	// to the template. If empty, the parameters that are declared in the metadata
+	// of a classic template are passed and none are passed to Flex templates.
+	Parameters []string
+
+	// Labels are added to the launched job.
+	Labels map[string]string
+}
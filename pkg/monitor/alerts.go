@@ -0,0 +1,52 @@
+package monitor
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/yannickalex07/dmon/pkg/storage"
+)
+
+// wasAlerted reports if an alert was already sent under the key. Storages that can't
+// hold values don't remember alerts, so these alerts are sent on every run.
+func wasAlerted(ctx context.Context, stateStore storage.Storage, key string) bool {
+	values, ok := stateStore.(storage.ValueStorage)
+	if !ok {
+		return false
+	}
+
+	_, found, err := values.GetValue(ctx, key)
+	if err != nil {
+		log.Errorf("failed to fetch if alert %s was sent: %s", key, err.Error())
+		return false
+	}
+
+	return found
+}
+
+// rememberAlert stores that an alert was sent, so that it is not sent again until the ttl passed.
+func rememberAlert(ctx context.Context, stateStore storage.Storage, key string, ttl time.Duration) {
+	values, ok := stateStore.(storage.ValueStorage)
+	if !ok {
+		return
+	}
+
+	err := values.SetValue(ctx, key, time.Now().UTC().Format(time.RFC3339), ttl)
+	if err != nil {
+		log.Errorf("failed to remember alert %s: %s", key, err.Error())
+	}
+}
+
+// forgetAlert deletes a sent alert, so that it is sent again the next time.
+func forgetAlert(ctx context.Context, stateStore storage.Storage, key string) {
+	values, ok := stateStore.(storage.ValueStorage)
+	if !ok {
+		return
+	}
+
+	err := values.DeleteValue(ctx, key)
+	if err != nil {
+		log.Errorf("failed to forget alert %s: %s", key, err.Error())
+	}
+}
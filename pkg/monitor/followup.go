@@ -0,0 +1,76 @@
+package monitor
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/yannickalex07/dmon/pkg/handler"
+	"github.com/yannickalex07/dmon/pkg/model"
+	"github.com/yannickalex07/dmon/pkg/storage"
+)
+
+// followUpKeyPrefix separates the limits of timed-out jobs from other values in the storage.
+const followUpKeyPrefix string = "followup:"
+
+// followUpRetention is how long a timed-out job is remembered for a follow-up.
+const followUpRetention time.Duration = 7 * 24 * time.Hour
+
+// rememberTimeout stores the limit that a job crossed, so that a follow-up can be
+// sent once the job finishes. Follow-ups require a storage that can hold values.
+func rememberTimeout(ctx context.Context, stateStore storage.Storage, job model.Job) {
+	values, ok := stateStore.(storage.ValueStorage)
+	if !ok {
+		return
+	}
+
+	err := values.SetValue(ctx, followUpKeyPrefix+job.Id, job.MaxRuntime.String(), followUpRetention)
+	if err != nil {
+		log.Errorf("failed to remember timeout of job %s: %s", job.Id, err.Error())
+	}
+}
+
+// followUp notifies the handlers if a job that timed out before reached a terminal state.
+func followUp(ctx context.Context, handlers []handler.Handler, stateStore storage.Storage, job model.Job) {
+	values, ok := stateStore.(storage.ValueStorage)
+	if !ok {
+		return
+	}
+
+	key := followUpKeyPrefix + job.Id
+	value, found, err := values.GetValue(ctx, key)
+	if err != nil {
+		log.Errorf("failed to fetch timeout of job %s: %s", job.Id, err.Error())
+		return
+	}
+
+	if !found {
+		return
+	}
+
+	limit, err := time.ParseDuration(value)
+	if err != nil {
+		log.Errorf("failed to parse stored timeout %s of job %s: %s", value, job.Id, err.Error())
+	} else {
+		job.MaxRuntime = limit
+	}
+
+	log.Infof("Job %s timed out before and is now %s - following up", job.Id, job.Status.Status)
+
+	for _, h := range handlers {
+		notifier, ok := h.(handler.FollowUpHandler)
+		if !ok {
+			continue
+		}
+
+		err := notifier.HandleFollowUp(ctx, job)
+		if err != nil {
+			log.Errorf("handler failed to follow up on job: %s", err.Error())
+		}
+	}
+
+	err = values.DeleteValue(ctx, key)
+	if err != nil {
+		log.Errorf("failed to delete timeout of job %s: %s", job.Id, err.Error())
+	}
+}
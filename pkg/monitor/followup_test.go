@@ -0,0 +1,76 @@
+package monitor_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yannickalex07/dmon/pkg/handler"
+	"github.com/yannickalex07/dmon/pkg/model"
+	"github.com/yannickalex07/dmon/pkg/monitor"
+	"github.com/yannickalex07/dmon/pkg/storage"
+)
+
+type FakeFollowUpHandler struct {
+	FakeHandler
+
+	FollowedUp []model.Job
+}
+
+func (f *FakeFollowUpHandler) HandleFollowUp(ctx context.Context, job model.Job) error {
+	f.FollowedUp = append(f.FollowedUp, job)
+	return nil
+}
+
+// This test asserts that a job which timed out is followed up on
+// exactly once after it reached a terminal state.
+func TestMonitorFollowsUpOnTimedOutJobs(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+
+	startTime := time.Now().UTC().Add(-3 * time.Hour)
+	running := model.Job{
+		Id:   "1",
+		Name: "job",
+		Type: "JOB_TYPE_BATCH",
+		Status: model.Status{
+			Status:    "JOB_STATE_RUNNING",
+			UpdatedAt: startTime,
+		},
+		StartTime: startTime,
+	}
+
+	stateStore := storage.NewMemoryStore(24 * time.Hour)
+	followUpHandler := FakeFollowUpHandler{}
+	handlers := []handler.Handler{&followUpHandler}
+
+	cfg := monitor.MonitorConfig{
+		MaxJobTimeout: time.Hour,
+	}
+
+	// - Act
+	timeoutErr := monitor.Monitor(ctx, cfg, FakeDataflow{FakeJobs: []FakeJob{{Job: running}}}, handlers, stateStore)
+
+	done := running
+	done.Status = model.Status{
+		Status:    "JOB_STATE_DONE",
+		UpdatedAt: time.Now().UTC(),
+	}
+	finished := FakeDataflow{FakeJobs: []FakeJob{{Job: done}}}
+
+	followUpErr := monitor.Monitor(ctx, cfg, finished, handlers, stateStore)
+
+	done.Status.UpdatedAt = time.Now().UTC()
+	repeatedErr := monitor.Monitor(ctx, cfg, FakeDataflow{FakeJobs: []FakeJob{{Job: done}}}, handlers, stateStore)
+
+	// - Assert
+	assert.Nil(t, timeoutErr)
+	assert.Nil(t, followUpErr)
+	assert.Nil(t, repeatedErr)
+
+	assert.Len(t, followUpHandler.HandledTimeouts, 1)
+	assert.Len(t, followUpHandler.FollowedUp, 1)
+	assert.Equal(t, "JOB_STATE_DONE", followUpHandler.FollowedUp[0].Status.Status)
+	assert.Equal(t, time.Hour, followUpHandler.FollowedUp[0].MaxRuntime)
+}
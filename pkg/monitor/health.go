@@ -0,0 +1,164 @@
+package monitor
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/yannickalex07/dmon/pkg/dataflow"
+	"github.com/yannickalex07/dmon/pkg/handler"
+	"github.com/yannickalex07/dmon/pkg/model"
+	"github.com/yannickalex07/dmon/pkg/storage"
+)
+
+// unhealthyKeyPrefix marks the streaming jobs that were reported as unhealthy.
+const unhealthyKeyPrefix string = "unhealthy:"
+
+// unhealthyRetention is how long an unhealthy job is not reported again, unless it recovers before.
+const unhealthyRetention time.Duration = 24 * time.Hour
+
+// StreamingConfig configures the health checks of running streaming jobs.
+// A threshold of zero disables the check of the metric.
+type StreamingConfig struct {
+	MaxDataWatermarkAge time.Duration
+	MaxSystemLag        time.Duration
+
+	// ConsecutiveChecks is the number of checks in a row that have to find
+	// a job unhealthy before it is reported, see config.StreamingConfig.RequiredChecks.
+	ConsecutiveChecks int
+
+	// Tracker keeps the number of consecutive unhealthy checks between runs.
+	Tracker *HealthTracker
+}
+
+func (c StreamingConfig) Enabled() bool {
+	return c.MaxDataWatermarkAge > 0 || c.MaxSystemLag > 0
+}
+
+// HealthTracker counts the consecutive unhealthy checks of streaming jobs.
+// The counts are kept in memory and start over after a restart.
+type HealthTracker struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func NewHealthTracker() *HealthTracker {
+	return &HealthTracker{
+		counts: map[string]int{},
+	}
+}
+
+// Record adds the result of a check and returns the number of consecutive unhealthy checks.
+// Without a tracker every unhealthy check counts on its own.
+func (h *HealthTracker) Record(id string, unhealthy bool) int {
+	if h == nil {
+		if unhealthy {
+			return 1
+		}
+
+		return 0
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if !unhealthy {
+		delete(h.counts, id)
+		return 0
+	}
+
+	h.counts[id]++
+	return h.counts[id]
+}
+
+// retain forgets all jobs that were not checked during the last run.
+func (h *HealthTracker) retain(ids map[string]bool) {
+	if h == nil {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for id := range h.counts {
+		if !ids[id] {
+			delete(h.counts, id)
+		}
+	}
+}
+
+// checkStreamingHealth requests the metrics of a running streaming job and notifies
+// the handlers once the job was unhealthy for the configured number of checks in a row.
+// A reported job that recovers is resolved.
+func checkStreamingHealth(ctx context.Context, cfg StreamingConfig, client dataflow.Dataflow, handlers []handler.Handler, stateStore storage.Storage, job model.Job) {
+	log.Debugf("Checking health of streaming job %s", job.Id)
+
+	metrics, err := client.StreamingMetrics(ctx, job)
+	if err != nil {
+		log.Errorf("Failed to query metrics for job %s with error %s", job.Id, err.Error())
+		return
+	}
+
+	health := model.StreamingHealth{
+		Metrics:             metrics,
+		MaxDataWatermarkAge: cfg.MaxDataWatermarkAge,
+		MaxSystemLag:        cfg.MaxSystemLag,
+	}
+
+	key := unhealthyKeyPrefix + job.Id
+
+	health.ConsecutiveChecks = cfg.Tracker.Record(job.Id, health.IsUnhealthy())
+	if health.ConsecutiveChecks == 0 {
+		// a recovered job is reported again once it becomes unhealthy again
+		if wasAlerted(ctx, stateStore, key) {
+			log.Infof("Streaming job %s recovered - resolving it", job.Id)
+			resolveRecoveredJob(ctx, handlers, job)
+			forgetAlert(ctx, stateStore, key)
+		}
+
+		return
+	}
+
+	log.Infof("Streaming job %s is unhealthy for %d consecutive checks (data watermark age %s, system lag %s)", job.Id, health.ConsecutiveChecks, metrics.DataWatermarkAge, metrics.SystemLag)
+
+	if health.ConsecutiveChecks < cfg.ConsecutiveChecks {
+		return
+	}
+
+	// check if notification for job was already send
+	if wasAlerted(ctx, stateStore, key) || isSnoozed(ctx, stateStore, job.Id) {
+		return
+	}
+
+	log.Infof("Notifying handlers for unhealthy streaming job %s", job.Id)
+
+	for _, h := range handlers {
+		notifier, ok := h.(handler.UnhealthyHandler)
+		if !ok {
+			continue
+		}
+
+		err := notifier.HandleUnhealthy(ctx, job, health)
+		if err != nil {
+			log.Errorf("handler failed to handle unhealthy job: %s", err.Error())
+		}
+	}
+
+	rememberAlert(ctx, stateStore, key, unhealthyRetention)
+}
+
+// resolveRecoveredJob notifies the handlers that a reported streaming job is healthy again.
+func resolveRecoveredJob(ctx context.Context, handlers []handler.Handler, job model.Job) {
+	for _, h := range handlers {
+		resolver, ok := h.(handler.ResolveHandler)
+		if !ok {
+			continue
+		}
+
+		err := resolver.HandleResolve(ctx, job)
+		if err != nil {
+			log.Errorf("handler failed to resolve recovered job: %s", err.Error())
+		}
+	}
+}
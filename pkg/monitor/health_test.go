@@ -0,0 +1,171 @@
+package monitor_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yannickalex07/dmon/pkg/handler"
+	"github.com/yannickalex07/dmon/pkg/model"
+	"github.com/yannickalex07/dmon/pkg/monitor"
+	"github.com/yannickalex07/dmon/pkg/storage"
+)
+
+type HandledUnhealthy struct {
+	Job    model.Job
+	Health model.StreamingHealth
+}
+
+type FakeUnhealthyHandler struct {
+	FakeHandler
+
+	HandledUnhealthy []HandledUnhealthy
+}
+
+func (f *FakeUnhealthyHandler) HandleUnhealthy(ctx context.Context, job model.Job, health model.StreamingHealth) error {
+	f.HandledUnhealthy = append(f.HandledUnhealthy, HandledUnhealthy{Job: job, Health: health})
+	return nil
+}
+
+type FakeRecoveryHandler struct {
+	FakeUnhealthyHandler
+
+	HandledResolves []model.Job
+}
+
+func (f *FakeRecoveryHandler) HandleResolve(ctx context.Context, job model.Job) error {
+	f.HandledResolves = append(f.HandledResolves, job)
+	return nil
+}
+
+// This test asserts that an unhealthy streaming job is reported once it was unhealthy
+// for the configured number of consecutive checks and that it is only reported once.
+func TestMonitorReportsUnhealthyStreamingJobs(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+
+	newStreamingJob := func(id string, metrics model.StreamingMetrics) FakeJob {
+		return FakeJob{
+			Job: model.Job{
+				Id:   id,
+				Name: id,
+				Type: "JOB_TYPE_STREAMING",
+				Status: model.Status{
+					Status:    "JOB_STATE_RUNNING",
+					UpdatedAt: time.Now().UTC().Add(-1 * time.Hour),
+				},
+				StartTime: time.Now().UTC().Add(-2 * time.Hour),
+			},
+			Metrics: metrics,
+		}
+	}
+
+	jobs := []FakeJob{
+		newStreamingJob("lagging", model.StreamingMetrics{SystemLag: 10 * time.Minute}),      // -> should be reported
+		newStreamingJob("healthy", model.StreamingMetrics{SystemLag: 10 * time.Second}),      // -> below threshold
+		newStreamingJob("stale", model.StreamingMetrics{DataWatermarkAge: 30 * time.Minute}), // -> should be reported
+	}
+
+	dataflow := FakeDataflow{FakeJobs: jobs}
+	stateStore := storage.NewMemoryStore(24 * time.Hour)
+	unhealthyHandler := FakeUnhealthyHandler{}
+
+	cfg := monitor.MonitorConfig{
+		MaxJobTimeout: 10 * time.Hour,
+		Streaming: monitor.StreamingConfig{
+			MaxDataWatermarkAge: 15 * time.Minute,
+			MaxSystemLag:        5 * time.Minute,
+			ConsecutiveChecks:   2,
+			Tracker:             monitor.NewHealthTracker(),
+		},
+	}
+
+	handlers := []handler.Handler{&unhealthyHandler}
+
+	// - Act & Assert
+	err := monitor.Monitor(ctx, cfg, dataflow, handlers, stateStore)
+	assert.Nil(t, err)
+	assert.Empty(t, unhealthyHandler.HandledUnhealthy, "jobs are only reported after two checks")
+
+	err = monitor.Monitor(ctx, cfg, dataflow, handlers, stateStore)
+	assert.Nil(t, err)
+	assert.Len(t, unhealthyHandler.HandledUnhealthy, 2)
+
+	assert.Equal(t, "lagging", unhealthyHandler.HandledUnhealthy[0].Job.Id)
+	assert.Equal(t, 2, unhealthyHandler.HandledUnhealthy[0].Health.ConsecutiveChecks)
+	assert.Equal(t, 5*time.Minute, unhealthyHandler.HandledUnhealthy[0].Health.MaxSystemLag)
+	assert.Equal(t, "stale", unhealthyHandler.HandledUnhealthy[1].Job.Id)
+
+	err = monitor.Monitor(ctx, cfg, dataflow, handlers, stateStore)
+	assert.Nil(t, err)
+	assert.Len(t, unhealthyHandler.HandledUnhealthy, 2, "jobs are only reported once")
+}
+
+// This test asserts that a job which recovered is resolved once and
+// reported again once it becomes unhealthy again.
+func TestMonitorReportsUnhealthyStreamingJobAgainAfterRecovery(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+
+	newStreamingJob := func(lag time.Duration) FakeDataflow {
+		return FakeDataflow{FakeJobs: []FakeJob{{
+			Job: model.Job{
+				Id:   "streaming",
+				Type: "JOB_TYPE_STREAMING",
+				Status: model.Status{
+					Status:    "JOB_STATE_RUNNING",
+					UpdatedAt: time.Now().UTC().Add(-1 * time.Hour),
+				},
+				StartTime: time.Now().UTC().Add(-2 * time.Hour),
+			},
+			Metrics: model.StreamingMetrics{SystemLag: lag},
+		}}}
+	}
+
+	lagging := newStreamingJob(10 * time.Minute)
+	recovered := newStreamingJob(10 * time.Second)
+
+	stateStore := storage.NewMemoryStore(24 * time.Hour)
+	recoveryHandler := FakeRecoveryHandler{}
+	handlers := []handler.Handler{&recoveryHandler}
+
+	cfg := monitor.MonitorConfig{
+		MaxJobTimeout: 10 * time.Hour,
+		Streaming: monitor.StreamingConfig{
+			MaxSystemLag:      5 * time.Minute,
+			ConsecutiveChecks: 1,
+			Tracker:           monitor.NewHealthTracker(),
+		},
+	}
+
+	// - Act
+	for _, dataflow := range []FakeDataflow{lagging, lagging, recovered, recovered, lagging} {
+		err := monitor.Monitor(ctx, cfg, dataflow, handlers, stateStore)
+		assert.Nil(t, err)
+	}
+
+	// - Assert
+	assert.Len(t, recoveryHandler.HandledUnhealthy, 2)
+
+	assert.Len(t, recoveryHandler.HandledResolves, 1, "a recovered job is resolved once")
+	assert.Equal(t, "streaming", recoveryHandler.HandledResolves[0].Id)
+}
+
+// This test asserts that a single healthy check resets the consecutive unhealthy checks.
+func TestHealthTrackerResetsOnHealthyCheck(t *testing.T) {
+	// - Arrange
+	tracker := monitor.NewHealthTracker()
+
+	// - Act
+	first := tracker.Record("job", true)
+	second := tracker.Record("job", true)
+	healthy := tracker.Record("job", false)
+	afterReset := tracker.Record("job", true)
+
+	// - Assert
+	assert.Equal(t, 1, first)
+	assert.Equal(t, 2, second)
+	assert.Equal(t, 0, healthy)
+	assert.Equal(t, 1, afterReset)
+}
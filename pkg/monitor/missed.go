@@ -0,0 +1,113 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/yannickalex07/dmon/pkg/dataflow"
+	"github.com/yannickalex07/dmon/pkg/model"
+	"github.com/yannickalex07/dmon/pkg/storage"
+)
+
+// missedRunsKey stores the clients whose jobs could not be listed during the last runs.
+const missedRunsKey string = "missed-runs"
+
+// missedRun remembers since when the jobs of a client are missing from the listing, so that
+// changes of these jobs are still reported once the client works again.
+type missedRun struct {
+	Project  string    `json:"project"`
+	Location string    `json:"location,omitempty"`
+	Since    time.Time `json:"since"`
+}
+
+// loadMissedRuns returns the clients that failed during the previous runs.
+func loadMissedRuns(ctx context.Context, stateStore storage.Storage) []missedRun {
+	values, ok := stateStore.(storage.ValueStorage)
+	if !ok {
+		return nil
+	}
+
+	value, found, err := values.GetValue(ctx, missedRunsKey)
+	if err != nil {
+		log.Errorf("failed to load missed runs: %s", err.Error())
+		return nil
+	}
+
+	if !found {
+		return nil
+	}
+
+	var missed []missedRun
+	err = json.Unmarshal([]byte(value), &missed)
+	if err != nil {
+		log.Errorf("failed to decode missed runs: %s", err.Error())
+		return nil
+	}
+
+	return missed
+}
+
+// missedSince returns the time after which changes of the job are reported. For jobs of a
+// client that failed during the previous runs, this is the last run that listed their jobs.
+func missedSince(missed []missedRun, job model.Job, lastExecutionTime time.Time) time.Time {
+	for _, run := range missed {
+		client := dataflow.DataflowClient{Project: run.Project, Location: run.Location}
+		if client.Covers(job) && run.Since.Before(lastExecutionTime) {
+			return run.Since
+		}
+	}
+
+	return lastExecutionTime
+}
+
+// storeMissedRuns remembers the clients that failed during this run, clients that work again are
+// forgotten. It reports if the clients could be stored, otherwise the latest execution time must
+// not be advanced, so that no changes of the missing jobs are lost.
+func storeMissedRuns(ctx context.Context, stateStore storage.Storage, previous []missedRun, partialErr *dataflow.PartialError, lastExecutionTime time.Time) bool {
+	if len(previous) == 0 && partialErr == nil {
+		return true
+	}
+
+	values, ok := stateStore.(storage.ValueStorage)
+	if !ok {
+		log.Warnf("The storage can't remember the projects whose jobs could not be listed, the latest execution time is kept")
+		return false
+	}
+
+	if partialErr == nil {
+		err := values.DeleteValue(ctx, missedRunsKey)
+		if err != nil {
+			log.Errorf("failed to delete missed runs: %s", err.Error())
+		}
+
+		return true
+	}
+
+	var missed []missedRun
+	for _, client := range partialErr.Failed {
+		run := missedRun{Project: client.Project, Location: client.Location, Since: lastExecutionTime}
+		for _, p := range previous {
+			if p.Project == run.Project && p.Location == run.Location {
+				run.Since = p.Since
+			}
+		}
+
+		missed = append(missed, run)
+	}
+
+	value, err := json.Marshal(missed)
+	if err != nil {
+		log.Errorf("failed to encode missed runs: %s", err.Error())
+		return false
+	}
+
+	err = values.SetValue(ctx, missedRunsKey, string(value), 0)
+	if err != nil {
+		log.Errorf("failed to store missed runs: %s", err.Error())
+		return false
+	}
+
+	return true
+}
@@ -2,18 +2,46 @@ package monitor
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/yannickalex07/dmon/pkg/dataflow"
 	"github.com/yannickalex07/dmon/pkg/handler"
+	"github.com/yannickalex07/dmon/pkg/metrics"
 	"github.com/yannickalex07/dmon/pkg/model"
 	"github.com/yannickalex07/dmon/pkg/storage"
 )
 
 type MonitorConfig struct {
 	MaxJobTimeout time.Duration
+
+	// TimeoutRules override the max timeout for specific jobs.
+	TimeoutRules []TimeoutRule
+
+	Streaming StreamingConfig
+
+	// StuckStates is the maximum time that a job may stay in each of the states.
+	StuckStates map[string]time.Duration
+
+	// HardLimit is the runtime after which batch jobs are cancelled, zero disables it.
+	HardLimit time.Duration
+
+	// StreamingHardLimit is the runtime after which streaming jobs are drained, zero disables it.
+	StreamingHardLimit time.Duration
+	Remediation        RemediationConfig
+
+	// RetryRules relaunch failed jobs from their template.
+	RetryRules []RetryRule
+
+	// Observer receives the jobs of every run, it is optional.
+	Observer JobObserver
+}
+
+// JobObserver is notified about the listed jobs of every run, i.e. to show them on a dashboard.
+type JobObserver interface {
+	ObserveJobs(jobs []model.Job)
 }
 
 func Monitor(ctx context.Context, cfg MonitorConfig, client dataflow.Dataflow, handlers []handler.Handler, stateStore storage.Storage) error {
@@ -29,19 +57,32 @@ func Monitor(ctx context.Context, cfg MonitorConfig, client dataflow.Dataflow, h
 	// Dataflow API request
 	log.Info("Requesting jobs from Dataflow API")
 
+	// the jobs of the working projects are still checked if only some projects failed
 	jobs, err := client.Jobs(ctx)
-	if err != nil {
+
+	var partialErr *dataflow.PartialError
+	if err != nil && !errors.As(err, &partialErr) {
 		wrappedErr := fmt.Errorf("failed to list jobs with error %w", err)
 		log.Errorf(wrappedErr.Error())
+		metrics.RecordRun(wrappedErr)
 
 		return wrappedErr
 	}
 
+	missed := loadMissedRuns(ctx, stateStore)
+
 	log.Debugf("Found %d jobs", len(jobs))
+	metrics.ObserveJobs(jobs)
+
+	if cfg.Observer != nil {
+		cfg.Observer.ObserveJobs(withMaxRuntime(cfg, jobs))
+	}
+
+	checkedStreamingJobs := map[string]bool{}
 
 	for _, job := range jobs {
-		// job was updated after last run
-		if job.Status.UpdatedAt.After(lastExecutionTime) {
+		// job was updated after last run, or after the last run that listed the jobs of its project
+		if job.Status.UpdatedAt.After(missedSince(missed, job, lastExecutionTime)) {
 			log.WithFields(log.Fields{
 				"id":        job.Id,
 				"name":      job.Name,
@@ -56,7 +97,7 @@ func Monitor(ctx context.Context, cfg MonitorConfig, client dataflow.Dataflow, h
 				// requesting error messages from Dataflow
 				log.Infof("Requesting error log entries for job %s", job.Id)
 
-				entries, err := client.ErrorLogs(ctx, job.Id)
+				entries, err := client.ErrorLogs(ctx, job)
 				if err != nil {
 					errMsg := fmt.Sprintf("Failed to query error entries for job %s with error %s", job.Id, err.Error())
 					log.Errorf(errMsg)
@@ -67,6 +108,9 @@ func Monitor(ctx context.Context, cfg MonitorConfig, client dataflow.Dataflow, h
 
 				log.Debugf("Found %d error entries for job %s", len(entries), job.Id)
 
+				// relaunching the job, the retry is part of the notification
+				job.Retry = retryFailedJob(ctx, cfg, client, stateStore, job)
+
 				// notifying handlers
 				log.Infof("Notifying handlers for failed job %s", job.Id)
 
@@ -79,6 +123,46 @@ func Monitor(ctx context.Context, cfg MonitorConfig, client dataflow.Dataflow, h
 
 				log.Debugf("Notified handlers for job %s", job.Id)
 			}
+
+			// handeling finished job, drained streaming jobs finished as well
+			if job.Status.IsDone() || job.Status.IsCanceled() || job.Status.IsDrained() {
+				log.Infof("Job %s has new terminal status %s - resolving it", job.Id, job.Status.Status)
+
+				for _, h := range handlers {
+					resolver, ok := h.(handler.ResolveHandler)
+					if !ok {
+						continue
+					}
+
+					err := resolver.HandleResolve(ctx, job)
+					if err != nil {
+						log.Errorf("handler failed to resolve job: %s", err.Error())
+					}
+				}
+			}
+
+			// handeling any other state change, failures are already reported above
+			if !job.Status.IsFailed() {
+				for _, h := range handlers {
+					notifier, ok := h.(handler.StateChangeHandler)
+					if !ok {
+						continue
+					}
+
+					err := notifier.HandleStateChange(ctx, job)
+					if err != nil {
+						log.Errorf("handler failed to handle state change: %s", err.Error())
+					}
+				}
+			}
+
+			// following up on jobs that timed out before
+			if job.Status.IsTerminal() {
+				followUp(ctx, handlers, stateStore, job)
+			}
+		} else if job.Status.IsFailed() {
+			// relaunching failed jobs whose retry was scheduled
+			launchPendingRetry(ctx, cfg, client, stateStore, job)
 		}
 
 		if job.Status.IsRunning() && !job.IsStreaming() {
@@ -89,9 +173,12 @@ func Monitor(ctx context.Context, cfg MonitorConfig, client dataflow.Dataflow, h
 			// check if time runs longer than allowed
 			log.Debugf("Checking if job %s has timeouted", job.Id)
 
-			if totalRunTime > cfg.MaxJobTimeout {
+			maxTimeout := cfg.MaxTimeoutFor(job)
+			job.MaxRuntime = maxTimeout
+
+			if totalRunTime > maxTimeout {
 
-				log.Infof("Job %s crossed max allowed timeout duration with a total runtime of %s", job.Id, totalRunTime.Round(time.Second))
+				log.Infof("Job %s crossed max allowed timeout duration of %s with a total runtime of %s", job.Id, maxTimeout, totalRunTime.Round(time.Second))
 
 				// check if notification for job was already send
 				isStored, err := stateStore.IsTimeoutStored(ctx, job.Id)
@@ -100,7 +187,7 @@ func Monitor(ctx context.Context, cfg MonitorConfig, client dataflow.Dataflow, h
 					isStored = false
 				}
 
-				if !isStored {
+				if !isStored && !isSnoozed(ctx, stateStore, job.Id) {
 					log.Infof("Timeout for job %s was not yet handled - handeling it now", job.Id)
 
 					for _, handler := range handlers {
@@ -115,18 +202,55 @@ func Monitor(ctx context.Context, cfg MonitorConfig, client dataflow.Dataflow, h
 						log.Errorf("failed to store timeout with err: %s", err.Error())
 					}
 
+					rememberTimeout(ctx, stateStore, job)
+
 					log.Infof("Timeout of job %s was handled", job.Id)
 				}
 			}
 		}
+
+		checkStuck(ctx, cfg, handlers, stateStore, job)
+		checkHardLimit(ctx, cfg, client, handlers, stateStore, job)
+
+		if job.Status.IsRunning() && job.IsStreaming() && cfg.Streaming.Enabled() {
+			checkedStreamingJobs[job.Id] = true
+			checkStreamingHealth(ctx, cfg.Streaming, client, handlers, stateStore, job)
+		}
 	}
 
-	err = stateStore.SetLatestExecutionTime(ctx, time.Now().UTC())
-	if err != nil {
-		log.Errorf("failed to set latest execution time: %s", err.Error())
+	cfg.Streaming.Tracker.retain(checkedStreamingJobs)
+
+	if storeMissedRuns(ctx, stateStore, missed, partialErr, lastExecutionTime) {
+		err = stateStore.SetLatestExecutionTime(ctx, time.Now().UTC())
+		if err != nil {
+			log.Errorf("failed to set latest execution time: %s", err.Error())
+		}
+	}
+
+	if partialErr != nil {
+		wrappedErr := fmt.Errorf("failed to list jobs of some projects with error %w", partialErr)
+		log.Errorf(wrappedErr.Error())
+		metrics.RecordPartialRun()
+
+		return wrappedErr
 	}
 
 	log.Info("Run finished.")
+	metrics.RecordRun(nil)
 
 	return nil
 }
+
+// withMaxRuntime returns a copy of the jobs where the max runtime of batch jobs is set.
+func withMaxRuntime(cfg MonitorConfig, jobs []model.Job) []model.Job {
+	observed := make([]model.Job, 0, len(jobs))
+	for _, job := range jobs {
+		if !job.IsStreaming() {
+			job.MaxRuntime = cfg.MaxTimeoutFor(job)
+		}
+
+		observed = append(observed, job)
+	}
+
+	return observed
+}
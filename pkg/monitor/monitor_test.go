@@ -7,9 +7,11 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/yannickalex07/dmon/pkg/dataflow"
 	"github.com/yannickalex07/dmon/pkg/handler"
 	"github.com/yannickalex07/dmon/pkg/model"
 	"github.com/yannickalex07/dmon/pkg/monitor"
+	"github.com/yannickalex07/dmon/pkg/storage"
 )
 
 // FAKES
@@ -19,6 +21,24 @@ import (
 type FakeJob struct {
 	Job     model.Job
 	Entries []model.LogEntry
+	Metrics model.StreamingMetrics
+}
+
+// newJob returns a batch job with the given state that was started an hour before its last update.
+func newJob(id string, status string, updatedAt time.Time) FakeJob {
+	return FakeJob{
+		Job: model.Job{
+			Id:   id,
+			Name: id,
+			Type: "JOB_TYPE_BATCH",
+			Status: model.Status{
+				UpdatedAt: updatedAt,
+				Status:    status,
+			},
+			StartTime: updatedAt.Add(-1 * time.Hour),
+		},
+		Entries: []model.LogEntry{},
+	}
 }
 
 type FakeDataflow struct {
@@ -26,6 +46,15 @@ type FakeDataflow struct {
 
 	JobsFetchError    error
 	EntriesFetchError error
+	MetricsFetchError error
+
+	// StateUpdates records the requested states per job id, if it is not nil.
+	StateUpdates     map[string]string
+	StateUpdateError error
+
+	// Launches records the launched templates per job id, if it is not nil.
+	Launches    map[string]model.TemplateLaunch
+	LaunchError error
 }
 
 func (f FakeDataflow) Jobs(ctx context.Context) ([]model.Job, error) {
@@ -38,9 +67,9 @@ func (f FakeDataflow) Jobs(ctx context.Context) ([]model.Job, error) {
 	return jobs, f.JobsFetchError
 }
 
-func (f FakeDataflow) ErrorLogs(ctx context.Context, jobId string) ([]model.LogEntry, error) {
+func (f FakeDataflow) ErrorLogs(ctx context.Context, job model.Job) ([]model.LogEntry, error) {
 	for _, j := range f.FakeJobs {
-		if j.Job.Id == jobId {
+		if j.Job.Id == job.Id {
 			return j.Entries, f.EntriesFetchError
 		}
 	}
@@ -48,6 +77,36 @@ func (f FakeDataflow) ErrorLogs(ctx context.Context, jobId string) ([]model.LogE
 	return []model.LogEntry{}, f.EntriesFetchError
 }
 
+func (f FakeDataflow) StreamingMetrics(ctx context.Context, job model.Job) (model.StreamingMetrics, error) {
+	for _, j := range f.FakeJobs {
+		if j.Job.Id == job.Id {
+			return j.Metrics, f.MetricsFetchError
+		}
+	}
+
+	return model.StreamingMetrics{}, f.MetricsFetchError
+}
+
+func (f FakeDataflow) UpdateState(ctx context.Context, job model.Job, state string) error {
+	if f.StateUpdates != nil {
+		f.StateUpdates[job.Id] = state
+	}
+
+	return f.StateUpdateError
+}
+
+func (f FakeDataflow) LaunchTemplate(ctx context.Context, job model.Job, launch model.TemplateLaunch) (string, error) {
+	if f.LaunchError != nil {
+		return "", f.LaunchError
+	}
+
+	if f.Launches != nil {
+		f.Launches[job.Id] = launch
+	}
+
+	return job.Id + "-retry", nil
+}
+
 // --- Handler
 
 type HandledErrors struct {
@@ -73,6 +132,28 @@ func (f *FakeHandler) HandleTimeout(ctx context.Context, job model.Job) error {
 	return f.HandleTimeoutError
 }
 
+type FakeResolveHandler struct {
+	FakeHandler
+
+	HandledResolves []model.Job
+}
+
+func (f *FakeResolveHandler) HandleResolve(ctx context.Context, job model.Job) error {
+	f.HandledResolves = append(f.HandledResolves, job)
+	return nil
+}
+
+type FakeStateChangeHandler struct {
+	FakeHandler
+
+	HandledStateChanges []model.Job
+}
+
+func (f *FakeStateChangeHandler) HandleStateChange(ctx context.Context, job model.Job) error {
+	f.HandledStateChanges = append(f.HandledStateChanges, job)
+	return nil
+}
+
 // --- StateStore
 
 type ExecutionTimeConfig struct {
@@ -272,8 +353,11 @@ func TestMonitor(t *testing.T) {
 		},
 	}, fakeHandler.HandledErrors)
 
-	// assert handle timeout called for "updated-3"
-	assert.Equal(t, []model.Job{jobs[2].Job}, fakeHandler.HandledTimeouts)
+	// assert handle timeout called for "updated-3" with the applied limit
+	timedOut := jobs[2].Job
+	timedOut.MaxRuntime = cfg.MaxJobTimeout
+
+	assert.Equal(t, []model.Job{timedOut}, fakeHandler.HandledTimeouts)
 }
 
 // This tests asserts the monitor behavior when the client fails to fetch
@@ -315,6 +399,86 @@ func TestMonitorFailsToFetchJobs(t *testing.T) {
 	assert.Error(t, err)
 }
 
+// This test asserts that the jobs of the working projects are still checked if a single project fails,
+// and that the changes of the failing project are reported once it works again.
+func TestMonitorReportsMissedJobsOfFailingProject(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	working := newJob("working", "JOB_STATE_FAILED", now.Add(-5*time.Minute))
+	working.Job.Project = "working-project"
+
+	missed := newJob("missed", "JOB_STATE_FAILED", now.Add(-5*time.Minute))
+	missed.Job.Project = "failing-project"
+
+	partialErr := &dataflow.PartialError{
+		Failed: []dataflow.DataflowClient{{Project: "failing-project"}},
+		Err:    errors.New("permission denied"),
+	}
+
+	failingClient := FakeDataflow{FakeJobs: []FakeJob{working}, JobsFetchError: partialErr}
+	recoveredClient := FakeDataflow{FakeJobs: []FakeJob{working, missed}}
+
+	stateStore := storage.NewMemoryStore(24 * time.Hour)
+	err := stateStore.SetLatestExecutionTime(ctx, now.Add(-10*time.Minute))
+	assert.Nil(t, err)
+
+	fakeHandler := FakeHandler{}
+	handlers := []handler.Handler{&fakeHandler}
+
+	cfg := monitor.MonitorConfig{
+		MaxJobTimeout: 10 * time.Hour,
+	}
+
+	// - Act
+	failingErr := monitor.Monitor(ctx, cfg, failingClient, handlers, stateStore)
+	recoveredErr := monitor.Monitor(ctx, cfg, recoveredClient, handlers, stateStore)
+	laterErr := monitor.Monitor(ctx, cfg, recoveredClient, handlers, stateStore)
+
+	// - Assert
+	assert.ErrorAs(t, failingErr, &partialErr)
+	assert.Nil(t, recoveredErr)
+	assert.Nil(t, laterErr)
+
+	assert.Len(t, fakeHandler.HandledErrors, 2)
+	assert.Equal(t, "working", fakeHandler.HandledErrors[0].Job.Id)
+	assert.Equal(t, "missed", fakeHandler.HandledErrors[1].Job.Id)
+}
+
+// This test asserts that the latest execution time is kept after a partial failure,
+// if the storage can't remember the failing projects.
+func TestMonitorKeepsExecutionTimeAfterPartialFailure(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+
+	client := FakeDataflow{
+		FakeJobs: []FakeJob{},
+		JobsFetchError: &dataflow.PartialError{
+			Failed: []dataflow.DataflowClient{{Project: "failing-project"}},
+			Err:    errors.New("permission denied"),
+		},
+	}
+
+	stateStore := &FakeStateStore{
+		TimeoutConfig: TimeoutConfig{
+			IsStoredMap: map[string]bool{},
+			Stored:      map[string]time.Time{},
+		},
+	}
+
+	cfg := monitor.MonitorConfig{
+		MaxJobTimeout: 1 * time.Hour,
+	}
+
+	// - Act
+	err := monitor.Monitor(ctx, cfg, client, make([]handler.Handler, 0), stateStore)
+
+	// - Assert
+	assert.Error(t, err)
+	assert.True(t, stateStore.ExecutionTimeConfig.SetValue.IsZero())
+}
+
 // This test asserts what happens when we fail to fetch the error logs for a given job.
 // Expected is that the handled will just receive empty error logs.
 func TestMonitorFailsToFetchErrorLogs(t *testing.T) {
@@ -534,5 +698,141 @@ func TestMonitorFailsTimeoutStoredCheck(t *testing.T) {
 
 	// - Assert
 	assert.Nil(t, err)
-	assert.Equal(t, []model.Job{jobs[0].Job}, fakeHandler.HandledTimeouts)
+
+	timedOut := jobs[0].Job
+	timedOut.MaxRuntime = cfg.MaxJobTimeout
+
+	assert.Equal(t, []model.Job{timedOut}, fakeHandler.HandledTimeouts)
+}
+
+// This test asserts that handlers which implement the ResolveHandler interface
+// are notified about jobs that finished or were canceled since the last run.
+func TestMonitorResolvesFinishedJobs(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	lastExecutionTime := time.Now().UTC()
+
+	jobs := []FakeJob{
+		newJob("done", "JOB_STATE_DONE", lastExecutionTime.Add(1*time.Minute)),           // -> should be resolved
+		newJob("cancelled", "JOB_STATE_CANCELLED", lastExecutionTime.Add(1*time.Minute)), // -> should be resolved
+		newJob("drained", "JOB_STATE_DRAINED", lastExecutionTime.Add(1*time.Minute)),     // -> should be resolved
+		newJob("failed", "JOB_STATE_FAILED", lastExecutionTime.Add(1*time.Minute)),       // -> should not be resolved
+		newJob("old", "JOB_STATE_DONE", lastExecutionTime.Add(-1*time.Minute)),           // -> was not updated
+	}
+
+	dataflow := FakeDataflow{FakeJobs: jobs}
+
+	stateStore := &FakeStateStore{
+		ExecutionTimeConfig: ExecutionTimeConfig{
+			GetValue: lastExecutionTime,
+		},
+		TimeoutConfig: TimeoutConfig{
+			IsStoredMap: map[string]bool{},
+			Stored:      map[string]time.Time{},
+		},
+	}
+
+	resolveHandler := FakeResolveHandler{}
+	plainHandler := FakeHandler{}
+
+	cfg := monitor.MonitorConfig{
+		MaxJobTimeout: 10 * time.Hour,
+	}
+
+	// - Act
+	err := monitor.Monitor(ctx, cfg, dataflow, []handler.Handler{&resolveHandler, &plainHandler}, stateStore)
+
+	// - Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []model.Job{jobs[0].Job, jobs[1].Job, jobs[2].Job}, resolveHandler.HandledResolves)
+	assert.Len(t, resolveHandler.HandledErrors, 1)
+	assert.Len(t, plainHandler.HandledErrors, 1)
+}
+
+// This test asserts that handlers which implement the StateChangeHandler interface
+// are notified about every new state except failures, which are handled separately.
+func TestMonitorNotifiesStateChanges(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	lastExecutionTime := time.Now().UTC()
+
+	jobs := []FakeJob{
+		newJob("done", "JOB_STATE_DONE", lastExecutionTime.Add(1*time.Minute)),           // -> should be notified
+		newJob("cancelled", "JOB_STATE_CANCELLED", lastExecutionTime.Add(1*time.Minute)), // -> should be notified
+		newJob("failed", "JOB_STATE_FAILED", lastExecutionTime.Add(1*time.Minute)),       // -> is handled as failure
+		newJob("old", "JOB_STATE_DONE", lastExecutionTime.Add(-1*time.Minute)),           // -> was not updated
+	}
+
+	dataflow := FakeDataflow{FakeJobs: jobs}
+
+	stateStore := &FakeStateStore{
+		ExecutionTimeConfig: ExecutionTimeConfig{
+			GetValue: lastExecutionTime,
+		},
+		TimeoutConfig: TimeoutConfig{
+			IsStoredMap: map[string]bool{},
+			Stored:      map[string]time.Time{},
+		},
+	}
+
+	stateChangeHandler := FakeStateChangeHandler{}
+
+	cfg := monitor.MonitorConfig{
+		MaxJobTimeout: 10 * time.Hour,
+	}
+
+	// - Act
+	err := monitor.Monitor(ctx, cfg, dataflow, []handler.Handler{&stateChangeHandler}, stateStore)
+
+	// - Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []model.Job{jobs[0].Job, jobs[1].Job}, stateChangeHandler.HandledStateChanges)
+	assert.Len(t, stateChangeHandler.HandledErrors, 1)
+}
+
+type FakeObserver struct {
+	Observed []model.Job
+}
+
+func (f *FakeObserver) ObserveJobs(jobs []model.Job) {
+	f.Observed = jobs
+}
+
+// This test asserts that the observer receives all listed jobs,
+// where batch jobs carry the max runtime that applies to them.
+func TestMonitorNotifiesObserver(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	lastExecutionTime := time.Now().UTC()
+
+	batch := model.Job{Id: "batch", Type: "JOB_TYPE_BATCH", Status: model.Status{Status: "JOB_STATE_DONE", UpdatedAt: lastExecutionTime.Add(-time.Minute)}}
+	streaming := model.Job{Id: "streaming", Type: "JOB_TYPE_STREAMING", Status: model.Status{Status: "JOB_STATE_DONE", UpdatedAt: lastExecutionTime.Add(-time.Minute)}}
+
+	dataflow := FakeDataflow{FakeJobs: []FakeJob{{Job: batch}, {Job: streaming}}}
+
+	stateStore := &FakeStateStore{
+		ExecutionTimeConfig: ExecutionTimeConfig{
+			GetValue: lastExecutionTime,
+		},
+		TimeoutConfig: TimeoutConfig{
+			IsStoredMap: map[string]bool{},
+			Stored:      map[string]time.Time{},
+		},
+	}
+
+	observer := FakeObserver{}
+
+	cfg := monitor.MonitorConfig{
+		MaxJobTimeout: 10 * time.Hour,
+		Observer:      &observer,
+	}
+
+	// - Act
+	err := monitor.Monitor(ctx, cfg, dataflow, []handler.Handler{}, stateStore)
+
+	// - Assert
+	assert.Nil(t, err)
+	assert.Len(t, observer.Observed, 2)
+	assert.Equal(t, 10*time.Hour, observer.Observed[0].MaxRuntime)
+	assert.Zero(t, observer.Observed[1].MaxRuntime)
 }
@@ -0,0 +1,231 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/yannickalex07/dmon/pkg/config"
+	"github.com/yannickalex07/dmon/pkg/dataflow"
+	"github.com/yannickalex07/dmon/pkg/handler"
+	"github.com/yannickalex07/dmon/pkg/model"
+	"github.com/yannickalex07/dmon/pkg/storage"
+)
+
+// remediationKeyPrefix marks the jobs that were cancelled or drained by dmon.
+const remediationKeyPrefix string = "remediation:"
+
+// remediationRetention is how long a remediated job is remembered, so that it is
+// not cancelled again while Dataflow is still shutting it down.
+const remediationRetention time.Duration = 7 * 24 * time.Hour
+
+// failedRemediationKeyPrefix marks the jobs whose remediation failed and is retried later.
+const failedRemediationKeyPrefix string = "remediation-failed:"
+
+// remediationBackoff is the wait before the first retry of a failed remediation, it doubles
+// with every further attempt up to remediationMaxBackoff.
+const remediationBackoff time.Duration = 5 * time.Minute
+const remediationMaxBackoff time.Duration = 6 * time.Hour
+
+// failedRemediation remembers a failed remediation, so that it is retried with a backoff
+// and the same error is only reported once.
+type failedRemediation struct {
+	Error    string    `json:"error"`
+	Attempts int       `json:"attempts"`
+	RetryAt  time.Time `json:"retry_at"`
+}
+
+// loadFailedRemediation returns the failed remediation of the job, if there is one.
+func loadFailedRemediation(ctx context.Context, stateStore storage.Storage, key string) (failedRemediation, bool) {
+	values, ok := stateStore.(storage.ValueStorage)
+	if !ok {
+		return failedRemediation{}, false
+	}
+
+	value, found, err := values.GetValue(ctx, key)
+	if err != nil {
+		log.Errorf("failed to load failed remediation %s: %s", key, err.Error())
+		return failedRemediation{}, false
+	}
+
+	if !found {
+		return failedRemediation{}, false
+	}
+
+	var failed failedRemediation
+	err = json.Unmarshal([]byte(value), &failed)
+	if err != nil {
+		log.Errorf("failed to decode failed remediation %s: %s", key, err.Error())
+		return failedRemediation{}, false
+	}
+
+	return failed, true
+}
+
+// storeFailedRemediation remembers another failed attempt and schedules the next retry.
+func storeFailedRemediation(ctx context.Context, stateStore storage.Storage, key string, previous failedRemediation, errStr string) {
+	values, ok := stateStore.(storage.ValueStorage)
+	if !ok {
+		return
+	}
+
+	backoff := remediationBackoff
+	for i := 0; i < previous.Attempts && backoff < remediationMaxBackoff; i++ {
+		backoff *= 2
+	}
+	backoff = min(backoff, remediationMaxBackoff)
+
+	failed := failedRemediation{
+		Error:    errStr,
+		Attempts: previous.Attempts + 1,
+		RetryAt:  time.Now().UTC().Add(backoff),
+	}
+
+	value, err := json.Marshal(failed)
+	if err != nil {
+		log.Errorf("failed to encode failed remediation %s: %s", key, err.Error())
+		return
+	}
+
+	err = values.SetValue(ctx, key, string(value), remediationRetention)
+	if err != nil {
+		log.Errorf("failed to store failed remediation %s: %s", key, err.Error())
+	}
+}
+
+// RemediationConfig controls the actions for jobs that exceed their hard limit.
+type RemediationConfig struct {
+	// DryRun only logs and reports the actions, without taking them.
+	DryRun bool
+
+	// Allowlist contains the patterns of job names that are never touched.
+	Allowlist []*regexp.Regexp
+}
+
+// NewRemediationConfig creates the remediation config and compiles the allowlist.
+func NewRemediationConfig(cfg config.RemediationConfig) (RemediationConfig, error) {
+	remediation := RemediationConfig{DryRun: cfg.DryRun}
+	for _, pattern := range cfg.Allowlist {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return RemediationConfig{}, fmt.Errorf("invalid allowlist pattern %s: %w", pattern, err)
+		}
+
+		remediation.Allowlist = append(remediation.Allowlist, re)
+	}
+
+	return remediation, nil
+}
+
+// Allows reports if actions may be taken for the job.
+func (c RemediationConfig) Allows(job model.Job) bool {
+	for _, re := range c.Allowlist {
+		if re.MatchString(job.Name) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// HardLimitFor returns the runtime after which the job is cancelled, or zero if the job
+// has no hard limit. The first matching rule with a hard limit takes precedence over the
+// global hard limits of batch and streaming jobs.
+func (c MonitorConfig) HardLimitFor(job model.Job) time.Duration {
+	for _, rule := range c.TimeoutRules {
+		if rule.HardLimit > 0 && rule.Matches(job) {
+			return rule.HardLimit
+		}
+	}
+
+	if job.IsStreaming() {
+		return c.StreamingHardLimit
+	}
+
+	return c.HardLimit
+}
+
+// checkHardLimit cancels running jobs that exceed their hard limit, streaming jobs
+// are drained instead. Every job is only remediated and reported once, failed
+// requests are retried with a backoff and only reported again if the error changes.
+func checkHardLimit(ctx context.Context, cfg MonitorConfig, client dataflow.Dataflow, handlers []handler.Handler, stateStore storage.Storage, job model.Job) {
+	if !job.Status.IsRunning() {
+		return
+	}
+
+	limit := cfg.HardLimitFor(job)
+	if limit <= 0 || job.Runtime() <= limit {
+		return
+	}
+
+	if !cfg.Remediation.Allows(job) {
+		log.Debugf("Job %s crossed its hard limit of %s but is on the allowlist", job.Id, limit)
+		return
+	}
+
+	// check if the job was already remediated
+	key := remediationKeyPrefix + job.Id
+	if wasAlerted(ctx, stateStore, key) {
+		return
+	}
+
+	failedKey := failedRemediationKeyPrefix + job.Id
+	failed, retried := loadFailedRemediation(ctx, stateStore, failedKey)
+	if retried && time.Now().UTC().Before(failed.RetryAt) {
+		log.Debugf("Remediation of job %s failed before, retrying after %s", job.Id, failed.RetryAt)
+		return
+	}
+
+	remediation := model.Remediation{
+		Action:    model.RemediationCancel,
+		HardLimit: limit,
+		DryRun:    cfg.Remediation.DryRun,
+	}
+
+	state := dataflow.StateCancelled
+	if job.IsStreaming() {
+		remediation.Action = model.RemediationDrain
+		state = dataflow.StateDrained
+	}
+
+	if remediation.DryRun {
+		log.Infof("Job %s crossed its hard limit of %s, dry run - not requesting %s", job.Id, limit, state)
+	} else {
+		log.Infof("Job %s crossed its hard limit of %s, requesting %s", job.Id, limit, state)
+
+		err := client.UpdateState(ctx, job, state)
+		if err != nil {
+			log.Errorf("failed to update state of job %s: %s", job.Id, err.Error())
+			remediation.Error = err.Error()
+		}
+	}
+
+	if remediation.Failed() {
+		storeFailedRemediation(ctx, stateStore, failedKey, failed, remediation.Error)
+		if retried && failed.Error == remediation.Error {
+			return
+		}
+	} else {
+		rememberAlert(ctx, stateStore, key, remediationRetention)
+		if retried {
+			forgetAlert(ctx, stateStore, failedKey)
+		}
+	}
+
+	job.MaxRuntime = cfg.MaxTimeoutFor(job)
+
+	for _, h := range handlers {
+		notifier, ok := h.(handler.RemediationHandler)
+		if !ok {
+			continue
+		}
+
+		err := notifier.HandleRemediation(ctx, job, remediation)
+		if err != nil {
+			log.Errorf("handler failed to handle remediation: %s", err.Error())
+		}
+	}
+}
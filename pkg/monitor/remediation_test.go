@@ -0,0 +1,209 @@
+package monitor_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yannickalex07/dmon/pkg/config"
+	"github.com/yannickalex07/dmon/pkg/handler"
+	"github.com/yannickalex07/dmon/pkg/model"
+	"github.com/yannickalex07/dmon/pkg/monitor"
+	"github.com/yannickalex07/dmon/pkg/storage"
+)
+
+type HandledRemediation struct {
+	JobId       string
+	Remediation model.Remediation
+}
+
+type FakeRemediationHandler struct {
+	FakeHandler
+
+	HandledRemediations []HandledRemediation
+}
+
+func (f *FakeRemediationHandler) HandleRemediation(ctx context.Context, job model.Job, remediation model.Remediation) error {
+	f.HandledRemediations = append(f.HandledRemediations, HandledRemediation{JobId: job.Id, Remediation: remediation})
+	return nil
+}
+
+func newRemediationConfig(t *testing.T, dryRun bool) monitor.MonitorConfig {
+	rules, err := monitor.NewTimeoutRules([]config.TimeoutRuleConfig{
+		{Name: "^stream-", MaxTimeout: 60, HardLimit: 120},
+	})
+	assert.Nil(t, err)
+
+	remediation, err := monitor.NewRemediationConfig(config.RemediationConfig{
+		DryRun:    dryRun,
+		Allowlist: []string{"^critical-"},
+	})
+	assert.Nil(t, err)
+
+	return monitor.MonitorConfig{
+		MaxJobTimeout:      time.Hour,
+		TimeoutRules:       rules,
+		HardLimit:          4 * time.Hour,
+		StreamingHardLimit: 6 * time.Hour,
+		Remediation:        remediation,
+	}
+}
+
+// This test asserts that jobs which exceed their hard limit are cancelled
+// or drained once and that jobs on the allowlist are never touched.
+func TestMonitorRemediatesJobsOverHardLimit(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	runningJobs := []struct {
+		id      string
+		jobType string
+		runtime time.Duration
+	}{
+		{"batch", "JOB_TYPE_BATCH", 5 * time.Hour},              // -> cancelled
+		{"stream-orders", "JOB_TYPE_STREAMING", 3 * time.Hour},  // -> drained because of the rule
+		{"streaming", "JOB_TYPE_STREAMING", 5 * time.Hour},      // -> below the streaming hard limit
+		{"long-streaming", "JOB_TYPE_STREAMING", 7 * time.Hour}, // -> drained because of the streaming hard limit
+		{"critical-batch", "JOB_TYPE_BATCH", 5 * time.Hour},     // -> on the allowlist
+		{"short", "JOB_TYPE_BATCH", 2 * time.Hour},              // -> below the hard limit
+	}
+
+	jobs := []FakeJob{}
+	for _, running := range runningJobs {
+		job := newJob(running.id, "JOB_STATE_RUNNING", now)
+		job.Job.Type = running.jobType
+		job.Job.StartTime = now.Add(-running.runtime)
+		jobs = append(jobs, job)
+	}
+
+	updates := map[string]string{}
+	dataflow := FakeDataflow{FakeJobs: jobs, StateUpdates: updates}
+	stateStore := storage.NewMemoryStore(24 * time.Hour)
+	remediationHandler := FakeRemediationHandler{}
+
+	cfg := newRemediationConfig(t, false)
+
+	// - Act
+	firstErr := monitor.Monitor(ctx, cfg, dataflow, []handler.Handler{&remediationHandler}, stateStore)
+	secondErr := monitor.Monitor(ctx, cfg, dataflow, []handler.Handler{&remediationHandler}, stateStore)
+
+	// - Assert
+	assert.Nil(t, firstErr)
+	assert.Nil(t, secondErr)
+
+	assert.Equal(t, map[string]string{
+		"batch":          "JOB_STATE_CANCELLED",
+		"stream-orders":  "JOB_STATE_DRAINED",
+		"long-streaming": "JOB_STATE_DRAINED",
+	}, updates)
+
+	assert.Equal(t, []HandledRemediation{
+		{JobId: "batch", Remediation: model.Remediation{Action: model.RemediationCancel, HardLimit: 4 * time.Hour}},
+		{JobId: "stream-orders", Remediation: model.Remediation{Action: model.RemediationDrain, HardLimit: 2 * time.Hour}},
+		{JobId: "long-streaming", Remediation: model.Remediation{Action: model.RemediationDrain, HardLimit: 6 * time.Hour}},
+	}, remediationHandler.HandledRemediations)
+}
+
+func TestMonitorRemediationDryRun(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+
+	job := newJob("batch", "JOB_STATE_RUNNING", time.Now().UTC())
+	job.Job.StartTime = time.Now().UTC().Add(-5 * time.Hour)
+
+	updates := map[string]string{}
+	dataflow := FakeDataflow{FakeJobs: []FakeJob{job}, StateUpdates: updates}
+	stateStore := storage.NewMemoryStore(24 * time.Hour)
+	remediationHandler := FakeRemediationHandler{}
+
+	cfg := newRemediationConfig(t, true)
+
+	// - Act
+	err := monitor.Monitor(ctx, cfg, dataflow, []handler.Handler{&remediationHandler}, stateStore)
+
+	// - Assert
+	assert.Nil(t, err)
+	assert.Empty(t, updates)
+	assert.Equal(t, []HandledRemediation{
+		{JobId: "batch", Remediation: model.Remediation{Action: model.RemediationCancel, HardLimit: 4 * time.Hour, DryRun: true}},
+	}, remediationHandler.HandledRemediations)
+}
+
+// This test asserts that failed remediations are retried after a backoff,
+// that the same error is only reported once and that the success is reported.
+func TestMonitorReportsAndRetriesFailedRemediation(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+
+	job := newJob("batch", "JOB_STATE_RUNNING", time.Now().UTC())
+	job.Job.StartTime = time.Now().UTC().Add(-5 * time.Hour)
+
+	attempts := map[string]string{}
+	updates := map[string]string{}
+	failingDataflow := FakeDataflow{
+		FakeJobs:         []FakeJob{job},
+		StateUpdates:     attempts,
+		StateUpdateError: errors.New("permission denied"),
+	}
+	workingDataflow := FakeDataflow{
+		FakeJobs:     failingDataflow.FakeJobs,
+		StateUpdates: updates,
+	}
+	stateStore := storage.NewMemoryStore(24 * time.Hour)
+	remediationHandler := FakeRemediationHandler{}
+
+	cfg := newRemediationConfig(t, false)
+
+	// the backoff of the failed remediation passed
+	expireBackoff := func() {
+		err := stateStore.SetValue(ctx, "remediation-failed:batch", `{"error":"permission denied","attempts":1,"retry_at":"2000-01-01T00:00:00Z"}`, 0)
+		assert.Nil(t, err)
+	}
+
+	// - Act
+	failedErr := monitor.Monitor(ctx, cfg, failingDataflow, []handler.Handler{&remediationHandler}, stateStore)
+	delete(attempts, "batch")
+	backoffErr := monitor.Monitor(ctx, cfg, failingDataflow, []handler.Handler{&remediationHandler}, stateStore)
+	backoffAttempts := len(attempts)
+
+	expireBackoff()
+	sameErr := monitor.Monitor(ctx, cfg, failingDataflow, []handler.Handler{&remediationHandler}, stateStore)
+	sameAttempts := len(attempts)
+
+	expireBackoff()
+	retriedErr := monitor.Monitor(ctx, cfg, workingDataflow, []handler.Handler{&remediationHandler}, stateStore)
+	doneErr := monitor.Monitor(ctx, cfg, workingDataflow, []handler.Handler{&remediationHandler}, stateStore)
+
+	// - Assert
+	assert.Nil(t, failedErr)
+	assert.Nil(t, backoffErr)
+	assert.Nil(t, sameErr)
+	assert.Nil(t, retriedErr)
+	assert.Nil(t, doneErr)
+
+	assert.Equal(t, 0, backoffAttempts)
+	assert.Equal(t, 1, sameAttempts)
+
+	assert.Len(t, remediationHandler.HandledRemediations, 2)
+	assert.Equal(t, "permission denied", remediationHandler.HandledRemediations[0].Remediation.Error)
+	assert.Empty(t, remediationHandler.HandledRemediations[1].Remediation.Error)
+	assert.Equal(t, map[string]string{"batch": "JOB_STATE_CANCELLED"}, updates)
+
+	_, found, err := stateStore.GetValue(ctx, "remediation-failed:batch")
+	assert.Nil(t, err)
+	assert.False(t, found)
+}
+
+func TestNewTimeoutRulesWithInvalidHardLimit(t *testing.T) {
+	// - Arrange
+	shorterThanTimeout := []config.TimeoutRuleConfig{{Name: "^hourly-", MaxTimeout: 60, HardLimit: 30}}
+
+	// - Act
+	_, err := monitor.NewTimeoutRules(shorterThanTimeout)
+
+	// - Assert
+	assert.Error(t, err)
+}
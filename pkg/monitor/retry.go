@@ -0,0 +1,294 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/yannickalex07/dmon/pkg/config"
+	"github.com/yannickalex07/dmon/pkg/dataflow"
+	"github.com/yannickalex07/dmon/pkg/model"
+	"github.com/yannickalex07/dmon/pkg/storage"
+)
+
+// RetryLabel is the Dataflow label that overrides the max retries of a single job.
+const RetryLabel string = "dmon-retry"
+
+// RetryOfLabel is set on relaunched jobs and contains the id of the job that failed first.
+const RetryOfLabel string = "dmon-retry-of"
+
+// retryKeyPrefix separates the retries of jobs from other values in the storage.
+const retryKeyPrefix string = "retry:"
+
+// retryRetention is how long the retries of a job are remembered.
+const retryRetention time.Duration = 7 * 24 * time.Hour
+
+// RetryRule relaunches failed jobs that match the rule from their template.
+// Empty fields match every job.
+type RetryRule struct {
+	Name       *regexp.Regexp
+	Labels     map[string]string
+	MaxRetries int
+
+	// Backoff is the wait time before the first retry, it doubles with every retry.
+	Backoff time.Duration
+
+	Launch model.TemplateLaunch
+}
+
+func (r RetryRule) Matches(job model.Job) bool {
+	return matchesJob(r.Name, r.Labels, job)
+}
+
+// NewRetryRules creates the retry rules from the config.
+func NewRetryRules(cfgs []config.RetryRuleConfig) ([]RetryRule, error) {
+	rules := make([]RetryRule, 0, len(cfgs))
+	for i, cfg := range cfgs {
+		if cfg.MaxRetries <= 0 {
+			return nil, fmt.Errorf("invalid retry rule %d: max retries has to be positive", i+1)
+		}
+
+		if cfg.Backoff < 0 {
+			return nil, fmt.Errorf("invalid retry rule %d: backoff can't be negative", i+1)
+		}
+
+		if cfg.FlexTemplate && cfg.TemplatePath == "" {
+			return nil, fmt.Errorf("invalid retry rule %d: flex templates require a template path", i+1)
+		}
+
+		rule := RetryRule{
+			Labels:     cfg.Labels,
+			MaxRetries: cfg.MaxRetries,
+			Backoff:    cfg.BackoffDuration(),
+			Launch: model.TemplateLaunch{
+				Path:       cfg.TemplatePath,
+				Flex:       cfg.FlexTemplate,
+				Parameters: cfg.Parameters,
+			},
+		}
+
+		if cfg.Name != "" {
+			re, err := regexp.Compile(cfg.Name)
+			if err != nil {
+				return nil, fmt.Errorf("invalid retry rule %d: %w", i+1, err)
+			}
+
+			rule.Name = re
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+// RetryRuleFor returns the first rule that matches the job. The label of
+// the job overrides the max retries of the rule and selects the job by itself
+// if no rule matches, the job is then relaunched from its classic template.
+// Only batch jobs are retried.
+func (c MonitorConfig) RetryRuleFor(job model.Job) (RetryRule, bool) {
+	if !job.IsBatch() {
+		return RetryRule{}, false
+	}
+
+	retries, labeled := retryLabel(job)
+
+	for _, rule := range c.RetryRules {
+		if !rule.Matches(job) {
+			continue
+		}
+
+		if labeled {
+			rule.MaxRetries = retries
+		}
+
+		return rule, true
+	}
+
+	if labeled {
+		return RetryRule{MaxRetries: retries}, true
+	}
+
+	return RetryRule{}, false
+}
+
+// retryLabel returns the max retries from the label of the job, if it has a valid one.
+func retryLabel(job model.Job) (int, bool) {
+	value, ok := job.Labels[RetryLabel]
+	if !ok {
+		return 0, false
+	}
+
+	retries, err := strconv.Atoi(value)
+	if err != nil || retries < 0 {
+		log.Warnf("Job %s has an invalid %s label %q, ignoring it", job.Id, RetryLabel, value)
+		return 0, false
+	}
+
+	return retries, true
+}
+
+// retryState tracks the retries of a job, across all of its relaunches.
+type retryState struct {
+	Attempts int `json:"attempts"`
+
+	// PendingJobId is the failed job that is relaunched once the backoff passed.
+	PendingJobId string    `json:"pending_job_id,omitempty"`
+	NotBefore    time.Time `json:"not_before,omitempty"`
+}
+
+// retryFailedJob relaunches a job that just failed or schedules its relaunch
+// if a backoff is configured. The returned retry is zero if the job is not retried.
+func retryFailedJob(ctx context.Context, cfg MonitorConfig, client dataflow.Dataflow, stateStore storage.Storage, job model.Job) model.Retry {
+	rule, ok := cfg.RetryRuleFor(job)
+	if !ok || rule.MaxRetries <= 0 {
+		return model.Retry{}
+	}
+
+	values, ok := stateStore.(storage.ValueStorage)
+	if !ok {
+		log.Warnf("Job %s matches a retry rule, but the storage does not support retries", job.Id)
+		return model.Retry{}
+	}
+
+	key := retryKeyPrefix + rootJobId(job)
+	state, err := loadRetryState(ctx, values, key)
+	if err != nil {
+		log.Errorf("failed to load retries of job %s: %s", job.Id, err.Error())
+		return model.Retry{}
+	}
+
+	if state.Attempts >= rule.MaxRetries {
+		log.Infof("Job %s failed after all %d retries", job.Id, rule.MaxRetries)
+		return model.Retry{Attempt: state.Attempts, MaxAttempts: rule.MaxRetries, Exhausted: true}
+	}
+
+	backoff := rule.Backoff * time.Duration(1<<state.Attempts)
+	if backoff > 0 {
+		state.PendingJobId = job.Id
+		state.NotBefore = time.Now().UTC().Add(backoff)
+
+		err := storeRetryState(ctx, values, key, state)
+		if err != nil {
+			log.Errorf("failed to schedule retry of job %s: %s", job.Id, err.Error())
+			return model.Retry{}
+		}
+
+		log.Infof("Scheduled retry %d/%d of job %s for %s", state.Attempts+1, rule.MaxRetries, job.Id, state.NotBefore)
+
+		return model.Retry{Attempt: state.Attempts + 1, MaxAttempts: rule.MaxRetries, ScheduledAt: state.NotBefore}
+	}
+
+	return launchRetry(ctx, rule, client, values, key, state, job)
+}
+
+// launchPendingRetry relaunches a failed job once the backoff of its scheduled retry passed.
+func launchPendingRetry(ctx context.Context, cfg MonitorConfig, client dataflow.Dataflow, stateStore storage.Storage, job model.Job) {
+	rule, ok := cfg.RetryRuleFor(job)
+	if !ok {
+		return
+	}
+
+	values, ok := stateStore.(storage.ValueStorage)
+	if !ok {
+		return
+	}
+
+	key := retryKeyPrefix + rootJobId(job)
+	state, err := loadRetryState(ctx, values, key)
+	if err != nil {
+		log.Errorf("failed to load retries of job %s: %s", job.Id, err.Error())
+		return
+	}
+
+	if state.PendingJobId != job.Id || time.Now().UTC().Before(state.NotBefore) {
+		return
+	}
+
+	launchRetry(ctx, rule, client, values, key, state, job)
+}
+
+// launchRetry relaunches the job from its template. The attempt is stored before the
+// launch, so that a job is never launched twice for the same attempt.
+func launchRetry(ctx context.Context, rule RetryRule, client dataflow.Dataflow, values storage.ValueStorage, key string, state retryState, job model.Job) model.Retry {
+	state.Attempts++
+	state.PendingJobId = ""
+	state.NotBefore = time.Time{}
+
+	retry := model.Retry{Attempt: state.Attempts, MaxAttempts: rule.MaxRetries}
+
+	err := storeRetryState(ctx, values, key, state)
+	if err != nil {
+		log.Errorf("failed to store retry of job %s: %s", job.Id, err.Error())
+		retry.Error = err.Error()
+		return retry
+	}
+
+	launch := rule.Launch
+	launch.Labels = retryLabels(job)
+
+	id, err := client.LaunchTemplate(ctx, job, launch)
+	if err != nil {
+		log.Errorf("failed to launch retry %d/%d of job %s: %s", retry.Attempt, retry.MaxAttempts, job.Id, err.Error())
+		retry.Error = err.Error()
+		return retry
+	}
+
+	log.Infof("Launched retry %d/%d of job %s as job %s", retry.Attempt, retry.MaxAttempts, job.Id, id)
+	retry.JobId = id
+
+	return retry
+}
+
+// rootJobId returns the id of the job that failed first.
+func rootJobId(job model.Job) string {
+	if id, ok := job.Labels[RetryOfLabel]; ok && id != "" {
+		return id
+	}
+
+	return job.Id
+}
+
+// retryLabels returns the labels of the relaunched job, which are the labels of
+// the failed job without the labels that Google sets.
+func retryLabels(job model.Job) map[string]string {
+	labels := map[string]string{}
+	for key, value := range job.Labels {
+		if !strings.HasPrefix(key, "goog-") {
+			labels[key] = value
+		}
+	}
+
+	labels[RetryOfLabel] = rootJobId(job)
+
+	return labels
+}
+
+func loadRetryState(ctx context.Context, values storage.ValueStorage, key string) (retryState, error) {
+	value, found, err := values.GetValue(ctx, key)
+	if err != nil || !found {
+		return retryState{}, err
+	}
+
+	var state retryState
+	err = json.Unmarshal([]byte(value), &state)
+	if err != nil {
+		return retryState{}, fmt.Errorf("failed to decode retries: %w", err)
+	}
+
+	return state, nil
+}
+
+func storeRetryState(ctx context.Context, values storage.ValueStorage, key string, state retryState) error {
+	value, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	return values.SetValue(ctx, key, string(value), retryRetention)
+}
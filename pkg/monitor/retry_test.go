@@ -0,0 +1,167 @@
+package monitor_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yannickalex07/dmon/pkg/config"
+	"github.com/yannickalex07/dmon/pkg/handler"
+	"github.com/yannickalex07/dmon/pkg/model"
+	"github.com/yannickalex07/dmon/pkg/monitor"
+	"github.com/yannickalex07/dmon/pkg/storage"
+)
+
+// This test asserts that a failed job is relaunched from its template, until all retries are used.
+func TestMonitorRetriesFailedJobs(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+
+	rules, err := monitor.NewRetryRules([]config.RetryRuleConfig{
+		{Name: "^etl-", MaxRetries: 1, TemplatePath: "gs://bucket/templates/etl"},
+	})
+	assert.Nil(t, err)
+
+	cfg := monitor.MonitorConfig{MaxJobTimeout: time.Hour, RetryRules: rules}
+	stateStore := storage.NewMemoryStore(24 * time.Hour)
+	fakeHandler := FakeHandler{}
+	handlers := []handler.Handler{&fakeHandler}
+
+	failed := newJob("1", "JOB_STATE_FAILED", time.Now().UTC())
+	failed.Job.Name = "etl-orders"
+	failed.Job.Labels = map[string]string{"team": "data", "goog-dataflow-provided-template-name": "etl"}
+
+	launches := map[string]model.TemplateLaunch{}
+	first := FakeDataflow{FakeJobs: []FakeJob{failed}, Launches: launches}
+
+	// - Act
+	firstErr := monitor.Monitor(ctx, cfg, first, handlers, stateStore)
+
+	// the relaunched job fails as well
+	relaunched := newJob("1-retry", "JOB_STATE_FAILED", time.Now().UTC())
+	relaunched.Job.Name = "etl-orders"
+	relaunched.Job.Labels = map[string]string{"team": "data", monitor.RetryOfLabel: "1"}
+
+	second := FakeDataflow{FakeJobs: []FakeJob{relaunched}, Launches: launches}
+
+	secondErr := monitor.Monitor(ctx, cfg, second, handlers, stateStore)
+
+	// - Assert
+	assert.Nil(t, firstErr)
+	assert.Nil(t, secondErr)
+
+	assert.Equal(t, map[string]model.TemplateLaunch{
+		"1": {
+			Path:   "gs://bucket/templates/etl",
+			Labels: map[string]string{"team": "data", monitor.RetryOfLabel: "1"},
+		},
+	}, launches)
+
+	assert.Len(t, fakeHandler.HandledErrors, 2)
+	assert.Equal(t, "retry 1/1 launched as job 1-retry", fakeHandler.HandledErrors[0].Job.Retry.String())
+	assert.Equal(t, "all 1 retries used", fakeHandler.HandledErrors[1].Job.Retry.String())
+}
+
+// This test asserts that a retry with a backoff is only launched once the backoff passed.
+func TestMonitorRetriesFailedJobsAfterBackoff(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+
+	cfg := monitor.MonitorConfig{
+		MaxJobTimeout: time.Hour,
+		RetryRules:    []monitor.RetryRule{{MaxRetries: 3, Backoff: 50 * time.Millisecond}},
+	}
+
+	stateStore := storage.NewMemoryStore(24 * time.Hour)
+	fakeHandler := FakeHandler{}
+	handlers := []handler.Handler{&fakeHandler}
+
+	launches := map[string]model.TemplateLaunch{}
+	dataflow := FakeDataflow{FakeJobs: []FakeJob{newJob("1", "JOB_STATE_FAILED", time.Now().UTC())}, Launches: launches}
+
+	// - Act
+	failedErr := monitor.Monitor(ctx, cfg, dataflow, handlers, stateStore)
+	launchesAfterFailure := len(launches)
+
+	time.Sleep(60 * time.Millisecond)
+	backoffErr := monitor.Monitor(ctx, cfg, dataflow, handlers, stateStore)
+
+	// - Assert
+	assert.Nil(t, failedErr)
+	assert.Nil(t, backoffErr)
+
+	assert.Equal(t, 0, launchesAfterFailure)
+	assert.Contains(t, launches, "1")
+
+	assert.Len(t, fakeHandler.HandledErrors, 1)
+	assert.Contains(t, fakeHandler.HandledErrors[0].Job.Retry.String(), "retry 1/3 scheduled for")
+}
+
+// This test asserts that failed streaming jobs are not relaunched, even if they match a rule.
+func TestMonitorDoesNotRetryStreamingJobs(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+
+	cfg := monitor.MonitorConfig{
+		MaxJobTimeout: time.Hour,
+		RetryRules:    []monitor.RetryRule{{MaxRetries: 3}},
+	}
+
+	stateStore := storage.NewMemoryStore(24 * time.Hour)
+	fakeHandler := FakeHandler{}
+	handlers := []handler.Handler{&fakeHandler}
+
+	failed := newJob("1", "JOB_STATE_FAILED", time.Now().UTC())
+	failed.Job.Type = "JOB_TYPE_STREAMING"
+
+	launches := map[string]model.TemplateLaunch{}
+	dataflow := FakeDataflow{FakeJobs: []FakeJob{failed}, Launches: launches}
+
+	// - Act
+	err := monitor.Monitor(ctx, cfg, dataflow, handlers, stateStore)
+
+	// - Assert
+	assert.Nil(t, err)
+	assert.Empty(t, launches)
+
+	assert.Len(t, fakeHandler.HandledErrors, 1)
+	assert.Equal(t, model.Retry{}, fakeHandler.HandledErrors[0].Job.Retry)
+}
+
+func TestRetryRuleFor(t *testing.T) {
+	// - Arrange
+	rules, err := monitor.NewRetryRules([]config.RetryRuleConfig{
+		{Labels: map[string]string{"kind": "etl"}, MaxRetries: 1},
+	})
+	assert.Nil(t, err)
+
+	cfg := monitor.MonitorConfig{RetryRules: rules}
+
+	matching := model.Job{Type: "JOB_TYPE_BATCH", Labels: map[string]string{"kind": "etl"}}
+	labeled := model.Job{Type: "JOB_TYPE_BATCH", Labels: map[string]string{"kind": "etl", monitor.RetryLabel: "3"}}
+	other := model.Job{Type: "JOB_TYPE_BATCH", Labels: map[string]string{monitor.RetryLabel: "3"}}
+	invalid := model.Job{Type: "JOB_TYPE_BATCH", Labels: map[string]string{monitor.RetryLabel: "always"}}
+	streaming := model.Job{Type: "JOB_TYPE_STREAMING", Labels: map[string]string{"kind": "etl", monitor.RetryLabel: "3"}}
+
+	// - Act
+	matchingRule, matchingOk := cfg.RetryRuleFor(matching)
+	labeledRule, labeledOk := cfg.RetryRuleFor(labeled)
+	otherRule, otherOk := cfg.RetryRuleFor(other)
+	_, invalidOk := cfg.RetryRuleFor(invalid)
+	_, streamingOk := cfg.RetryRuleFor(streaming)
+
+	// - Assert
+	assert.True(t, matchingOk)
+	assert.Equal(t, 1, matchingRule.MaxRetries)
+
+	assert.True(t, labeledOk)
+	assert.Equal(t, 3, labeledRule.MaxRetries) // -> label overrides the rule
+
+	assert.True(t, otherOk) // -> the label alone enables retries
+	assert.Equal(t, 3, otherRule.MaxRetries)
+	assert.Equal(t, model.TemplateLaunch{}, otherRule.Launch)
+
+	assert.False(t, invalidOk)
+	assert.False(t, streamingOk) // -> only batch jobs are retried
+}
@@ -0,0 +1,41 @@
+package monitor
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/yannickalex07/dmon/pkg/storage"
+)
+
+// snoozeKeyPrefix separates snoozed jobs from other values in the storage.
+const snoozeKeyPrefix string = "snooze:"
+
+// Snooze mutes the repeating alerts of a job for the given duration. Timeouts, stuck
+// and unhealthy jobs are not reported while a job is snoozed, failures and state
+// changes are still reported.
+func Snooze(ctx context.Context, store storage.ValueStorage, jobId string, duration time.Duration) error {
+	until := time.Now().UTC().Add(duration)
+	return store.SetValue(ctx, snoozeKeyPrefix+jobId, until.Format(time.RFC3339), duration)
+}
+
+// isSnoozed checks if the alerts of a job are muted. Storages that can't hold
+// values don't support snoozing.
+func isSnoozed(ctx context.Context, stateStore storage.Storage, jobId string) bool {
+	values, ok := stateStore.(storage.ValueStorage)
+	if !ok {
+		return false
+	}
+
+	_, found, err := values.GetValue(ctx, snoozeKeyPrefix+jobId)
+	if err != nil {
+		log.Errorf("failed to fetch if job %s is snoozed: %s", jobId, err.Error())
+		return false
+	}
+
+	if found {
+		log.Infof("Job %s is snoozed, skipping notification", jobId)
+	}
+
+	return found
+}
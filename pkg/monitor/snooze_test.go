@@ -0,0 +1,42 @@
+package monitor_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yannickalex07/dmon/pkg/handler"
+	"github.com/yannickalex07/dmon/pkg/monitor"
+	"github.com/yannickalex07/dmon/pkg/storage"
+)
+
+// This test asserts that timeouts of snoozed jobs are not reported.
+func TestMonitorSkipsSnoozedJobs(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+
+	updatedAt := time.Now().UTC().Add(-2 * time.Hour)
+
+	dataflow := FakeDataflow{FakeJobs: []FakeJob{
+		newJob("snoozed", "JOB_STATE_RUNNING", updatedAt),
+		newJob("timeout", "JOB_STATE_RUNNING", updatedAt),
+	}}
+	stateStore := storage.NewMemoryStore(24 * time.Hour)
+	fakeHandler := FakeHandler{}
+
+	cfg := monitor.MonitorConfig{
+		MaxJobTimeout: time.Hour,
+	}
+
+	err := monitor.Snooze(ctx, stateStore, "snoozed", time.Hour)
+	assert.Nil(t, err)
+
+	// - Act
+	err = monitor.Monitor(ctx, cfg, dataflow, []handler.Handler{&fakeHandler}, stateStore)
+
+	// - Assert
+	assert.Nil(t, err)
+	assert.Len(t, fakeHandler.HandledTimeouts, 1)
+	assert.Equal(t, "timeout", fakeHandler.HandledTimeouts[0].Id)
+}
@@ -0,0 +1,80 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/yannickalex07/dmon/pkg/handler"
+	"github.com/yannickalex07/dmon/pkg/model"
+	"github.com/yannickalex07/dmon/pkg/storage"
+)
+
+// stuckKeyPrefix marks the states that a job was reported as stuck in.
+const stuckKeyPrefix string = "stuck:"
+
+// stuckRetention is how long a stuck job is remembered, jobs rarely stay in a state for longer.
+const stuckRetention time.Duration = 7 * 24 * time.Hour
+
+// NewStuckStates converts the configured maximum dwell times in minutes per state into durations.
+// Only states that a job can leave again are allowed.
+func NewStuckStates(cfg map[string]int) (map[string]time.Duration, error) {
+	states := make(map[string]time.Duration, len(cfg))
+	for state, minutes := range cfg {
+		if !strings.HasPrefix(state, "JOB_STATE_") {
+			return nil, fmt.Errorf("invalid stuck state %s, states have to look like JOB_STATE_QUEUED", state)
+		}
+
+		if (model.Status{Status: state}).IsTerminal() {
+			return nil, fmt.Errorf("invalid stuck state %s, jobs never leave terminal states", state)
+		}
+
+		if minutes <= 0 {
+			return nil, fmt.Errorf("invalid stuck state %s, the max duration has to be positive", state)
+		}
+
+		states[state] = time.Duration(minutes) * time.Minute
+	}
+
+	return states, nil
+}
+
+// checkStuck notifies the handlers if the job stays in its current state for longer than allowed.
+// Every job is reported once per state.
+func checkStuck(ctx context.Context, cfg MonitorConfig, handlers []handler.Handler, stateStore storage.Storage, job model.Job) {
+	limit, ok := cfg.StuckStates[job.Status.Status]
+	if !ok {
+		return
+	}
+
+	dwell := time.Since(job.Status.UpdatedAt)
+	if dwell <= limit {
+		return
+	}
+
+	log.Infof("Job %s is in state %s for %s, which is longer than the allowed %s", job.Id, job.Status.Status, dwell.Round(time.Second), limit)
+
+	// check if notification for job was already send
+	key := stuckKeyPrefix + job.Id + ":" + job.Status.Status
+	if wasAlerted(ctx, stateStore, key) || isSnoozed(ctx, stateStore, job.Id) {
+		return
+	}
+
+	log.Infof("Notifying handlers for stuck job %s", job.Id)
+
+	for _, h := range handlers {
+		notifier, ok := h.(handler.StuckHandler)
+		if !ok {
+			continue
+		}
+
+		err := notifier.HandleStuck(ctx, job, limit)
+		if err != nil {
+			log.Errorf("handler failed to handle stuck job: %s", err.Error())
+		}
+	}
+
+	rememberAlert(ctx, stateStore, key, stuckRetention)
+}
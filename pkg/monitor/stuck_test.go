@@ -0,0 +1,88 @@
+package monitor_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yannickalex07/dmon/pkg/handler"
+	"github.com/yannickalex07/dmon/pkg/model"
+	"github.com/yannickalex07/dmon/pkg/monitor"
+	"github.com/yannickalex07/dmon/pkg/storage"
+)
+
+type HandledStuck struct {
+	Job   model.Job
+	Limit time.Duration
+}
+
+type FakeStuckHandler struct {
+	FakeHandler
+
+	HandledStuck []HandledStuck
+}
+
+func (f *FakeStuckHandler) HandleStuck(ctx context.Context, job model.Job, limit time.Duration) error {
+	f.HandledStuck = append(f.HandledStuck, HandledStuck{Job: job, Limit: limit})
+	return nil
+}
+
+// This test asserts that jobs which stay in a state for longer than allowed
+// are reported once per state.
+func TestMonitorReportsStuckJobs(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+
+	jobs := []FakeJob{
+		newJob("queued", "JOB_STATE_QUEUED", now.Add(-2*time.Hour)),         // -> should be reported
+		newJob("pending", "JOB_STATE_PENDING", now.Add(-10*time.Minute)),    // -> below the limit
+		newJob("cancelling", "JOB_STATE_CANCELLING", now.Add(-2*time.Hour)), // -> state is not checked
+	}
+
+	dataflow := FakeDataflow{FakeJobs: jobs}
+	stateStore := storage.NewMemoryStore(24 * time.Hour)
+	stuckHandler := FakeStuckHandler{}
+
+	stuckStates, err := monitor.NewStuckStates(map[string]int{
+		"JOB_STATE_QUEUED":  60,
+		"JOB_STATE_PENDING": 30,
+	})
+	assert.Nil(t, err)
+
+	cfg := monitor.MonitorConfig{
+		MaxJobTimeout: 10 * time.Hour,
+		StuckStates:   stuckStates,
+	}
+
+	handlers := []handler.Handler{&stuckHandler}
+
+	// - Act
+	firstErr := monitor.Monitor(ctx, cfg, dataflow, handlers, stateStore)
+	secondErr := monitor.Monitor(ctx, cfg, dataflow, handlers, stateStore)
+
+	// - Assert
+	assert.Nil(t, firstErr)
+	assert.Nil(t, secondErr)
+
+	assert.Equal(t, []HandledStuck{{Job: jobs[0].Job, Limit: 60 * time.Minute}}, stuckHandler.HandledStuck)
+}
+
+func TestNewStuckStatesWithInvalidConfig(t *testing.T) {
+	// - Arrange
+	invalidName := map[string]int{"queued": 60}
+	terminalState := map[string]int{"JOB_STATE_DONE": 60}
+	missingDuration := map[string]int{"JOB_STATE_QUEUED": 0}
+
+	// - Act
+	_, nameErr := monitor.NewStuckStates(invalidName)
+	_, terminalErr := monitor.NewStuckStates(terminalState)
+	_, durationErr := monitor.NewStuckStates(missingDuration)
+
+	// - Assert
+	assert.Error(t, nameErr)
+	assert.Error(t, terminalErr)
+	assert.Error(t, durationErr)
+}
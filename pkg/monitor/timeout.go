@@ -0,0 +1,103 @@
+package monitor
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/yannickalex07/dmon/pkg/config"
+	"github.com/yannickalex07/dmon/pkg/model"
+)
+
+// MaxRuntimeLabel is the Dataflow label that overrides the max timeout of a single job,
+// the value is a duration like 90m or 2h.
+const MaxRuntimeLabel string = "dmon-max-runtime"
+
+// TimeoutRule overrides the max timeout for all jobs that match the rule.
+// Empty fields match every job.
+type TimeoutRule struct {
+	Name       *regexp.Regexp
+	Labels     map[string]string
+	MaxTimeout time.Duration
+
+	// HardLimit is the runtime after which the job is cancelled, zero if the rule sets no hard limit.
+	HardLimit time.Duration
+}
+
+func (r TimeoutRule) Matches(job model.Job) bool {
+	return matchesJob(r.Name, r.Labels, job)
+}
+
+// matchesJob reports if the name of the job matches the regex and if the job has all labels.
+func matchesJob(name *regexp.Regexp, labels map[string]string, job model.Job) bool {
+	if name != nil && !name.MatchString(job.Name) {
+		return false
+	}
+
+	for key, value := range labels {
+		if job.Labels[key] != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+// NewTimeoutRules creates the timeout rules from the config.
+func NewTimeoutRules(cfgs []config.TimeoutRuleConfig) ([]TimeoutRule, error) {
+	rules := make([]TimeoutRule, 0, len(cfgs))
+	for i, cfg := range cfgs {
+		if cfg.MaxTimeout <= 0 {
+			return nil, fmt.Errorf("invalid timeout rule %d: max timeout has to be positive", i+1)
+		}
+
+		if cfg.HardLimit < 0 {
+			return nil, fmt.Errorf("invalid timeout rule %d: hard limit can't be negative", i+1)
+		}
+
+		if cfg.HardLimit > 0 && cfg.HardLimit <= cfg.MaxTimeout {
+			return nil, fmt.Errorf("invalid timeout rule %d: hard limit has to be longer than the max timeout", i+1)
+		}
+
+		rule := TimeoutRule{
+			Labels:     cfg.Labels,
+			MaxTimeout: cfg.MaxTimeoutDuration(),
+			HardLimit:  cfg.HardLimitDuration(),
+		}
+
+		if cfg.Name != "" {
+			re, err := regexp.Compile(cfg.Name)
+			if err != nil {
+				return nil, fmt.Errorf("invalid timeout rule %d: %w", i+1, err)
+			}
+
+			rule.Name = re
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+// MaxTimeoutFor returns the max timeout of the job. The label of the job takes
+// precedence over the first matching rule, which takes precedence over the global max timeout.
+func (c MonitorConfig) MaxTimeoutFor(job model.Job) time.Duration {
+	if value, ok := job.Labels[MaxRuntimeLabel]; ok {
+		timeout, err := time.ParseDuration(value)
+		if err == nil && timeout > 0 {
+			return timeout
+		}
+
+		log.Warnf("Job %s has an invalid %s label %q, ignoring it", job.Id, MaxRuntimeLabel, value)
+	}
+
+	for _, rule := range c.TimeoutRules {
+		if rule.Matches(job) {
+			return rule.MaxTimeout
+		}
+	}
+
+	return c.MaxJobTimeout
+}
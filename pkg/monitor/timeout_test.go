@@ -0,0 +1,52 @@
+package monitor_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yannickalex07/dmon/pkg/config"
+	"github.com/yannickalex07/dmon/pkg/model"
+	"github.com/yannickalex07/dmon/pkg/monitor"
+)
+
+func TestMaxTimeoutFor(t *testing.T) {
+	// - Arrange
+	rules, err := monitor.NewTimeoutRules([]config.TimeoutRuleConfig{
+		{Name: "^hourly-", MaxTimeout: 50},
+		{Labels: map[string]string{"kind": "backfill"}, MaxTimeout: 360},
+	})
+	assert.Nil(t, err)
+
+	cfg := monitor.MonitorConfig{
+		MaxJobTimeout: 10 * time.Minute,
+		TimeoutRules:  rules,
+	}
+
+	hourly := model.Job{Name: "hourly-orders"}
+	backfill := model.Job{Name: "orders", Labels: map[string]string{"kind": "backfill"}}
+	labeled := model.Job{Name: "hourly-orders", Labels: map[string]string{monitor.MaxRuntimeLabel: "90m"}}
+	invalidLabel := model.Job{Name: "orders", Labels: map[string]string{monitor.MaxRuntimeLabel: "forever"}}
+	other := model.Job{Name: "orders"}
+
+	// - Act & Assert
+	assert.Equal(t, 50*time.Minute, cfg.MaxTimeoutFor(hourly))
+	assert.Equal(t, 6*time.Hour, cfg.MaxTimeoutFor(backfill))
+	assert.Equal(t, 90*time.Minute, cfg.MaxTimeoutFor(labeled))      // label wins over the matching rule
+	assert.Equal(t, 10*time.Minute, cfg.MaxTimeoutFor(invalidLabel)) // invalid labels are ignored
+	assert.Equal(t, 10*time.Minute, cfg.MaxTimeoutFor(other))
+}
+
+func TestNewTimeoutRulesWithInvalidConfig(t *testing.T) {
+	// - Arrange
+	invalidPattern := []config.TimeoutRuleConfig{{Name: "(", MaxTimeout: 10}}
+	missingTimeout := []config.TimeoutRuleConfig{{Name: "^hourly-"}}
+
+	// - Act
+	_, patternErr := monitor.NewTimeoutRules(invalidPattern)
+	_, timeoutErr := monitor.NewTimeoutRules(missingTimeout)
+
+	// - Assert
+	assert.Error(t, patternErr)
+	assert.Error(t, timeoutErr)
+}
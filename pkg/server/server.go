@@ -0,0 +1,77 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// shutdownTimeout is how long running requests may take to finish on shutdown.
+const shutdownTimeout = 5 * time.Second
+
+// Server serves the HTTP endpoints of dmon, i.e. for interactive Slack messages.
+type Server struct {
+	Address string
+
+	mux *http.ServeMux
+}
+
+func New(address string) *Server {
+	return &Server{
+		Address: address,
+		mux:     http.NewServeMux(),
+	}
+}
+
+// Handle registers the handler for the given pattern.
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
+// ServeHTTP makes the registered handlers testable without starting the server.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Run listens on the address and serves requests until the context is canceled.
+func (s *Server) Run(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.Address)
+	if err != nil {
+		return err
+	}
+
+	return s.Serve(ctx, listener)
+}
+
+// Serve serves requests on the listener until the context is canceled.
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	server := &http.Server{
+		Handler:           s.mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		err := server.Shutdown(shutdownCtx)
+		if err != nil {
+			log.Errorf("failed to shut down server: %s", err.Error())
+		}
+	}()
+
+	log.Infof("Serving HTTP endpoints on %s", listener.Addr())
+
+	err := server.Serve(listener)
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+
+	return err
+}
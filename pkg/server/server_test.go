@@ -0,0 +1,47 @@
+package server_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yannickalex07/dmon/pkg/server"
+)
+
+// This test asserts that registered handlers are served
+// and that the server stops once the context is canceled.
+func TestServerServe(t *testing.T) {
+	// - Arrange
+	ctx, cancel := context.WithCancel(context.Background())
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+
+	s := server.New(listener.Addr().String())
+	s.Handle("/ping", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("pong"))
+	}))
+
+	done := make(chan error)
+	go func() {
+		done <- s.Serve(ctx, listener)
+	}()
+
+	// - Act
+	res, err := http.Get("http://" + listener.Addr().String() + "/ping")
+	assert.Nil(t, err)
+
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+
+	cancel()
+	serveErr := <-done
+
+	// - Assert
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "pong", string(body))
+	assert.Nil(t, serveErr)
+}
@@ -0,0 +1,255 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// fileState is the on-disk representation of the state kept by the FileStorage.
+type fileState struct {
+	LastRunTime time.Time                `json:"last_run_time"`
+	Timeouts    map[string]storedTimeout `json:"timeouts"`
+	Values      map[string]storedValue   `json:"values,omitempty"`
+}
+
+type storedTimeout struct {
+	StoredAt time.Time `json:"stored_at"`
+
+	// ExpiresAt is zero for timeouts that never expire.
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (t storedTimeout) isExpired(now time.Time) bool {
+	return !t.ExpiresAt.IsZero() && !now.Before(t.ExpiresAt)
+}
+
+type storedValue struct {
+	Value string `json:"value"`
+
+	// ExpiresAt is zero for values that never expire.
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (v storedValue) isExpired(now time.Time) bool {
+	return !v.ExpiresAt.IsZero() && !now.Before(v.ExpiresAt)
+}
+
+// FileStorage persists the state as a JSON document on the local disk,
+// so that it survives restarts of dmon.
+type FileStorage struct {
+	path string
+	ttl  time.Duration
+
+	mu sync.Mutex
+}
+
+func NewFileStore(path string, ttl time.Duration) (*FileStorage, error) {
+	s := &FileStorage{
+		path: path,
+		ttl:  ttl,
+	}
+
+	// create the initial state if the file does not exist yet
+	_, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		state := fileState{
+			LastRunTime: time.Now().UTC(),
+			Timeouts:    map[string]storedTimeout{},
+		}
+
+		err = s.write(state)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize state file %s: %w", path, err)
+	}
+
+	return s, nil
+}
+
+func (s *FileStorage) GetLatestExecutionTime(ctx context.Context) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, err := s.read()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return state.LastRunTime, nil
+}
+
+func (s *FileStorage) SetLatestExecutionTime(ctx context.Context, t time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	state.LastRunTime = t
+
+	return s.write(state)
+}
+
+func (s *FileStorage) IsTimeoutStored(ctx context.Context, id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, err := s.read()
+	if err != nil {
+		return false, err
+	}
+
+	timeout, ok := state.Timeouts[id]
+	if !ok {
+		return false, nil
+	}
+
+	return !timeout.isExpired(time.Now()), nil
+}
+
+func (s *FileStorage) StoreTimeout(ctx context.Context, id string, t time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	timeout := storedTimeout{StoredAt: t}
+	if s.ttl > 0 {
+		timeout.ExpiresAt = time.Now().Add(s.ttl)
+	}
+
+	state.Timeouts[id] = timeout
+
+	return s.write(state)
+}
+
+func (s *FileStorage) GetValue(ctx context.Context, key string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, err := s.read()
+	if err != nil {
+		return "", false, err
+	}
+
+	value, ok := state.Values[key]
+	if !ok || value.isExpired(time.Now()) {
+		return "", false, nil
+	}
+
+	return value.Value, true, nil
+}
+
+func (s *FileStorage) SetValue(ctx context.Context, key string, value string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	stored := storedValue{Value: value}
+	if ttl > 0 {
+		stored.ExpiresAt = time.Now().Add(ttl)
+	}
+
+	state.Values[key] = stored
+
+	return s.write(state)
+}
+
+func (s *FileStorage) DeleteValue(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	delete(state.Values, key)
+
+	return s.write(state)
+}
+
+func (s *FileStorage) read() (fileState, error) {
+	content, err := os.ReadFile(s.path)
+	if err != nil {
+		return fileState{}, fmt.Errorf("failed to read state file: %w", err)
+	}
+
+	var state fileState
+	err = json.Unmarshal(content, &state)
+	if err != nil {
+		return fileState{}, fmt.Errorf("failed to decode state file: %w", err)
+	}
+
+	if state.Timeouts == nil {
+		state.Timeouts = map[string]storedTimeout{}
+	}
+
+	if state.Values == nil {
+		state.Values = map[string]storedValue{}
+	}
+
+	return state, nil
+}
+
+// write replaces the state file atomically by writing into a temporary file
+// first and moving it to the final path afterwards. Expired timeouts and values are
+// dropped on every write to keep the file from growing indefinitely.
+func (s *FileStorage) write(state fileState) error {
+	now := time.Now()
+	for id, timeout := range state.Timeouts {
+		if timeout.isExpired(now) {
+			delete(state.Timeouts, id)
+		}
+	}
+
+	for key, value := range state.Values {
+		if value.isExpired(now) {
+			delete(state.Values, key)
+		}
+	}
+
+	content, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary state file: %w", err)
+	}
+
+	_, err = tmp.Write(content)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write temporary state file: %w", err)
+	}
+
+	err = os.Rename(tmp.Name(), s.path)
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to replace state file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,167 @@
+package storage_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yannickalex07/dmon/pkg/storage"
+)
+
+func TestFileStoreGetAndSetExecutionTime(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	storage, err := storage.NewFileStore(path, 1*time.Hour)
+	assert.Nil(t, err)
+
+	// we append some time to make sure no time.Now() can be accidentally correct
+	lastExecutionTime := time.Now().Add(1 * time.Hour)
+
+	// - Act
+	storeError := storage.SetLatestExecutionTime(ctx, lastExecutionTime)
+	fetchedTime, fetchError := storage.GetLatestExecutionTime(ctx)
+
+	// - Assert
+	assert.Nil(t, storeError)
+	assert.Nil(t, fetchError)
+	assert.True(t, lastExecutionTime.Equal(fetchedTime))
+}
+
+func TestFileStoreIsTimeoutStoredWithNonStoredTimeout(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	storage, err := storage.NewFileStore(path, 1*time.Hour)
+	assert.Nil(t, err)
+
+	// - Act
+	handled, err := storage.IsTimeoutStored(ctx, "job-id")
+
+	// - Assert
+	assert.Nil(t, err)
+	assert.False(t, handled)
+}
+
+func TestFileStoreIsTimeoutStoredWithStoredTimeout(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	storage, err := storage.NewFileStore(path, 1*time.Hour)
+	assert.Nil(t, err)
+
+	// - Act
+	storeError := storage.StoreTimeout(ctx, "job-id", time.Now())
+	handled, fetchError := storage.IsTimeoutStored(ctx, "job-id")
+
+	// - Assert
+	assert.Nil(t, storeError)
+	assert.Nil(t, fetchError)
+	assert.True(t, handled)
+}
+
+func TestFileStoreIsTimeoutStoredWithExpiredTimeout(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	// every timeout should expire after 1 second
+	storage, err := storage.NewFileStore(path, 1*time.Second)
+	assert.Nil(t, err)
+
+	// - Act
+	storeError := storage.StoreTimeout(ctx, "job-id", time.Now())
+
+	time.Sleep(2 * time.Second)
+
+	handled, fetchError := storage.IsTimeoutStored(ctx, "job-id")
+
+	// - Assert
+	assert.Nil(t, storeError)
+	assert.Nil(t, fetchError)
+	assert.False(t, handled)
+}
+
+// This test asserts that timeouts never expire without a ttl, like in the other stores.
+func TestFileStoreIsTimeoutStoredWithoutTTL(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	storage, err := storage.NewFileStore(path, 0)
+	assert.Nil(t, err)
+
+	// - Act
+	storeError := storage.StoreTimeout(ctx, "job-id", time.Now())
+	setError := storage.SetLatestExecutionTime(ctx, time.Now()) // -> rewrites the file
+	handled, fetchError := storage.IsTimeoutStored(ctx, "job-id")
+
+	// - Assert
+	assert.Nil(t, storeError)
+	assert.Nil(t, setError)
+	assert.Nil(t, fetchError)
+	assert.True(t, handled)
+}
+
+// This test asserts that a new store that is opened on an existing
+// file picks up the state that was written by a previous store.
+func TestFileStoreSurvivesRestart(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	lastExecutionTime := time.Now().Add(-1 * time.Hour).UTC()
+
+	previous, err := storage.NewFileStore(path, 1*time.Hour)
+	assert.Nil(t, err)
+
+	assert.Nil(t, previous.SetLatestExecutionTime(ctx, lastExecutionTime))
+	assert.Nil(t, previous.StoreTimeout(ctx, "job-id", time.Now()))
+
+	// - Act
+	restarted, err := storage.NewFileStore(path, 1*time.Hour)
+	assert.Nil(t, err)
+
+	fetchedTime, fetchTimeError := restarted.GetLatestExecutionTime(ctx)
+	handled, fetchTimeoutError := restarted.IsTimeoutStored(ctx, "job-id")
+
+	// - Assert
+	assert.Nil(t, fetchTimeError)
+	assert.Nil(t, fetchTimeoutError)
+	assert.True(t, lastExecutionTime.Equal(fetchedTime))
+	assert.True(t, handled)
+}
+
+func TestFileStoreValues(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	storage, err := storage.NewFileStore(path, 1*time.Hour)
+	assert.Nil(t, err)
+
+	// - Act
+	setErr := storage.SetValue(ctx, "key", "value", 0)
+	expiredErr := storage.SetValue(ctx, "expired", "value", 1*time.Nanosecond)
+	value, found, getErr := storage.GetValue(ctx, "key")
+	_, expiredFound, _ := storage.GetValue(ctx, "expired")
+
+	deleteErr := storage.DeleteValue(ctx, "key")
+	_, foundAfterDelete, _ := storage.GetValue(ctx, "key")
+
+	// - Assert
+	assert.Nil(t, setErr)
+	assert.Nil(t, expiredErr)
+	assert.Nil(t, getErr)
+	assert.Nil(t, deleteErr)
+
+	assert.True(t, found)
+	assert.Equal(t, "value", value)
+	assert.False(t, expiredFound)
+	assert.False(t, foundAfterDelete)
+}
@@ -9,6 +9,7 @@ import (
 
 type MemoryStorage struct {
 	cache       *ttlcache.Cache[string, string]
+	values      *ttlcache.Cache[string, string]
 	lastRunTime time.Time
 }
 
@@ -17,10 +18,14 @@ func NewMemoryStore(ttl time.Duration) *MemoryStorage {
 		ttlcache.WithTTL[string, string](ttl),
 	)
 
+	values := ttlcache.New[string, string]()
+
 	go cache.Start()
+	go values.Start()
 
 	return &MemoryStorage{
 		cache:       cache,
+		values:      values,
 		lastRunTime: time.Now().UTC(),
 	}
 }
@@ -43,3 +48,26 @@ func (s *MemoryStorage) StoreTimeout(ctx context.Context, id string, t time.Time
 	s.cache.Set(id, t.Format(time.RFC3339), ttlcache.DefaultTTL)
 	return nil
 }
+
+func (s MemoryStorage) GetValue(ctx context.Context, key string) (string, bool, error) {
+	item := s.values.Get(key)
+	if item == nil {
+		return "", false, nil
+	}
+
+	return item.Value(), true, nil
+}
+
+func (s *MemoryStorage) SetValue(ctx context.Context, key string, value string, ttl time.Duration) error {
+	if ttl <= 0 {
+		ttl = ttlcache.NoTTL
+	}
+
+	s.values.Set(key, value, ttl)
+	return nil
+}
+
+func (s *MemoryStorage) DeleteValue(ctx context.Context, key string) error {
+	s.values.Delete(key)
+	return nil
+}
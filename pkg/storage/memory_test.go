@@ -77,3 +77,25 @@ func TestMemoryStoreIsTimeoutStoredWithExpiredTimeout(t *testing.T) {
 	assert.Nil(t, fetchError)
 	assert.False(t, handled)
 }
+
+func TestMemoryStoreValues(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	storage := storage.NewMemoryStore(1 * time.Hour)
+
+	// - Act
+	setErr := storage.SetValue(ctx, "key", "value", 0)
+	value, found, getErr := storage.GetValue(ctx, "key")
+
+	deleteErr := storage.DeleteValue(ctx, "key")
+	_, foundAfterDelete, _ := storage.GetValue(ctx, "key")
+
+	// - Assert
+	assert.Nil(t, setErr)
+	assert.Nil(t, getErr)
+	assert.Nil(t, deleteErr)
+
+	assert.True(t, found)
+	assert.Equal(t, "value", value)
+	assert.False(t, foundAfterDelete)
+}
@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	redisExecutionTimeKey string = "last-execution-time"
+	redisTimeoutKeyPrefix string = "timeout:"
+	redisValueKeyPrefix   string = "value:"
+)
+
+// RedisStorage keeps the state in Redis, which allows multiple
+// instances of dmon to share the same state.
+type RedisStorage struct {
+	client *redis.Client
+	prefix string
+	ttl    time.Duration
+}
+
+func NewRedisStore(client *redis.Client, prefix string, ttl time.Duration) *RedisStorage {
+	return &RedisStorage{
+		client: client,
+		prefix: prefix,
+		ttl:    ttl,
+	}
+}
+
+func (s RedisStorage) GetLatestExecutionTime(ctx context.Context) (time.Time, error) {
+	key := s.prefix + redisExecutionTimeKey
+
+	// if no execution time was stored yet, we initialize it with the current time.
+	// SetNX makes sure that all instances agree on the same initial time.
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+	err := s.client.SetNX(ctx, key, now, 0).Err()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	value, err := s.client.Get(ctx, key).Result()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Parse(time.RFC3339Nano, value)
+}
+
+func (s *RedisStorage) SetLatestExecutionTime(ctx context.Context, t time.Time) error {
+	key := s.prefix + redisExecutionTimeKey
+	return s.client.Set(ctx, key, t.UTC().Format(time.RFC3339Nano), 0).Err()
+}
+
+func (s RedisStorage) IsTimeoutStored(ctx context.Context, id string) (bool, error) {
+	key := s.prefix + redisTimeoutKeyPrefix + id
+
+	err := s.client.Get(ctx, key).Err()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (s *RedisStorage) StoreTimeout(ctx context.Context, id string, t time.Time) error {
+	key := s.prefix + redisTimeoutKeyPrefix + id
+	return s.client.Set(ctx, key, t.UTC().Format(time.RFC3339), s.ttl).Err()
+}
+
+func (s RedisStorage) GetValue(ctx context.Context, key string) (string, bool, error) {
+	value, err := s.client.Get(ctx, s.prefix+redisValueKeyPrefix+key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", false, nil
+	}
+
+	if err != nil {
+		return "", false, err
+	}
+
+	return value, true, nil
+}
+
+func (s *RedisStorage) SetValue(ctx context.Context, key string, value string, ttl time.Duration) error {
+	if ttl < 0 {
+		ttl = 0
+	}
+
+	return s.client.Set(ctx, s.prefix+redisValueKeyPrefix+key, value, ttl).Err()
+}
+
+func (s *RedisStorage) DeleteValue(ctx context.Context, key string) error {
+	return s.client.Del(ctx, s.prefix+redisValueKeyPrefix+key).Err()
+}
@@ -0,0 +1,163 @@
+package storage_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/yannickalex07/dmon/pkg/storage"
+)
+
+func newRedisClient(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+
+	t.Cleanup(func() { client.Close() })
+
+	return server, client
+}
+
+func TestRedisStoreGetAndSetExecutionTime(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	_, client := newRedisClient(t)
+
+	storage := storage.NewRedisStore(client, "dmon:", 1*time.Hour)
+
+	// we append some time to make sure no time.Now() can be accidentally correct
+	lastExecutionTime := time.Now().Add(1 * time.Hour)
+
+	// - Act
+	storeError := storage.SetLatestExecutionTime(ctx, lastExecutionTime)
+	fetchedTime, fetchError := storage.GetLatestExecutionTime(ctx)
+
+	// - Assert
+	assert.Nil(t, storeError)
+	assert.Nil(t, fetchError)
+	assert.True(t, lastExecutionTime.Equal(fetchedTime))
+}
+
+// This test asserts that the first fetch of the execution time initializes
+// the value, so that all following fetches return the same time.
+func TestRedisStoreGetExecutionTimeWithoutStoredTime(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	_, client := newRedisClient(t)
+
+	storage := storage.NewRedisStore(client, "dmon:", 1*time.Hour)
+
+	// - Act
+	first, firstError := storage.GetLatestExecutionTime(ctx)
+	second, secondError := storage.GetLatestExecutionTime(ctx)
+
+	// - Assert
+	assert.Nil(t, firstError)
+	assert.Nil(t, secondError)
+	assert.False(t, first.IsZero())
+	assert.True(t, first.Equal(second))
+}
+
+func TestRedisStoreIsTimeoutStoredWithNonStoredTimeout(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	_, client := newRedisClient(t)
+
+	storage := storage.NewRedisStore(client, "dmon:", 1*time.Hour)
+
+	// - Act
+	handled, err := storage.IsTimeoutStored(ctx, "job-id")
+
+	// - Assert
+	assert.Nil(t, err)
+	assert.False(t, handled)
+}
+
+func TestRedisStoreIsTimeoutStoredWithStoredTimeout(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	_, client := newRedisClient(t)
+
+	storage := storage.NewRedisStore(client, "dmon:", 1*time.Hour)
+
+	// - Act
+	storeError := storage.StoreTimeout(ctx, "job-id", time.Now())
+	handled, fetchError := storage.IsTimeoutStored(ctx, "job-id")
+
+	// - Assert
+	assert.Nil(t, storeError)
+	assert.Nil(t, fetchError)
+	assert.True(t, handled)
+}
+
+func TestRedisStoreIsTimeoutStoredWithExpiredTimeout(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	server, client := newRedisClient(t)
+
+	storage := storage.NewRedisStore(client, "dmon:", 1*time.Minute)
+
+	// - Act
+	storeError := storage.StoreTimeout(ctx, "job-id", time.Now())
+
+	server.FastForward(2 * time.Minute)
+
+	handled, fetchError := storage.IsTimeoutStored(ctx, "job-id")
+
+	// - Assert
+	assert.Nil(t, storeError)
+	assert.Nil(t, fetchError)
+	assert.False(t, handled)
+}
+
+func TestRedisStoreFailsWithUnreachableServer(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	server, client := newRedisClient(t)
+
+	storage := storage.NewRedisStore(client, "dmon:", 1*time.Hour)
+
+	server.Close()
+
+	// - Act
+	_, fetchError := storage.GetLatestExecutionTime(ctx)
+	_, isStoredError := storage.IsTimeoutStored(ctx, "job-id")
+
+	// - Assert
+	assert.Error(t, fetchError)
+	assert.Error(t, isStoredError)
+}
+
+func TestRedisStoreValues(t *testing.T) {
+	// - Arrange
+	ctx := context.Background()
+	server, client := newRedisClient(t)
+
+	storage := storage.NewRedisStore(client, "dmon:", 1*time.Hour)
+
+	// - Act
+	setErr := storage.SetValue(ctx, "key", "value", 0)
+	expiringErr := storage.SetValue(ctx, "expiring", "value", 1*time.Minute)
+
+	server.FastForward(2 * time.Minute)
+
+	value, found, getErr := storage.GetValue(ctx, "key")
+	_, expiringFound, _ := storage.GetValue(ctx, "expiring")
+
+	deleteErr := storage.DeleteValue(ctx, "key")
+	_, foundAfterDelete, _ := storage.GetValue(ctx, "key")
+
+	// - Assert
+	assert.Nil(t, setErr)
+	assert.Nil(t, expiringErr)
+	assert.Nil(t, getErr)
+	assert.Nil(t, deleteErr)
+
+	assert.True(t, found)
+	assert.Equal(t, "value", value)
+	assert.False(t, server.Exists("dmon:value:expiring"))
+	assert.False(t, expiringFound)
+	assert.False(t, foundAfterDelete)
+}
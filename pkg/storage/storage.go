@@ -12,3 +12,11 @@ type Storage interface {
 	IsTimeoutStored(ctx context.Context, id string) (bool, error)
 	StoreTimeout(ctx context.Context, id string, t time.Time) error
 }
+
+// ValueStorage is implemented by storages that can keep arbitrary values, i.e. to
+// remember details about reported jobs between runs. A ttl of zero keeps a value forever.
+type ValueStorage interface {
+	GetValue(ctx context.Context, key string) (string, bool, error)
+	SetValue(ctx context.Context, key string, value string, ttl time.Duration) error
+	DeleteValue(ctx context.Context, key string) error
+}